@@ -0,0 +1,82 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// An Alert contains the description of a legacy panel alert
+type Alert struct {
+	ID          int    `json:"id"`
+	DashboardID int    `json:"dashboardId"`
+	PanelID     int    `json:"panelId"`
+	Name        string `json:"name"`
+	State       string `json:"state"`
+}
+
+// ListDashboardAlerts returns the legacy alerts of the panels of a dashboard.
+func (s *Session) ListDashboardAlerts(dashboardID int) (alerts []Alert, err error) {
+	reqURL := fmt.Sprintf("%s/api/alerts?dashboardId=%d", s.url, dashboardID)
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&alerts)
+	return
+}
+
+// PauseAlert pauses or resumes a legacy alert.
+func (s *Session) PauseAlert(alertID int, paused bool) (err error) {
+	reqURL := fmt.Sprintf("%s/api/alerts/%d/pause", s.url, alertID)
+	jsonStr, _ := json.Marshal(map[string]bool{"paused": paused})
+	_, err = s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	return
+}
+
+// PauseDashboardAlerts pauses or resumes every legacy alert of a dashboard, e.g. during a deploy.
+func (s *Session) PauseDashboardAlerts(uid string, paused bool) (err error) {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	dashRes, err := s.GetDashboardByUID(uid)
+	if err != nil {
+		return
+	}
+	alerts, err := s.ListDashboardAlerts(dashRes.Model.ID)
+	if err != nil {
+		return
+	}
+	for _, alert := range alerts {
+		if err = s.PauseAlert(alert.ID, paused); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// A NotificationChannel contains where legacy alerts are sent, e.g. type "slack" with a "url" setting
+type NotificationChannel struct {
+	ID           int                    `json:"id,omitempty"`
+	UID          string                 `json:"uid,omitempty"`
+	Name         string                 `json:"name"`
+	Type         string                 `json:"type"`
+	IsDefault    bool                   `json:"isDefault"`
+	SendReminder bool                   `json:"sendReminder"`
+	Settings     map[string]interface{} `json:"settings"`
+}
+
+// CreateNotificationChannel creates a notification channel and returns its id.
+func (s *Session) CreateNotificationChannel(ch NotificationChannel) (id int, err error) {
+	reqURL := s.url + "/api/alert-notifications"
+	jsonStr, _ := json.Marshal(ch)
+	body, err := s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	if err != nil {
+		return
+	}
+	var res NotificationChannel
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&res)
+	return res.ID, err
+}
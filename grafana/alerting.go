@@ -0,0 +1,185 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// States an alert rule takes when its queries return no data or fail
+const (
+	AlertStateAlerting = "Alerting"
+	AlertStateNoData   = "NoData"
+	AlertStateOK       = "OK"
+	AlertStateError    = "Error"
+)
+
+// An AlertRule contains a Grafana Alerting rule, the unified alerting of Grafana 9 and later.
+// Condition is the refId of the query or expression deciding whether the rule fires,
+// For how long it must fire before alerting, e.g. "5m".
+type AlertRule struct {
+	UID          string            `json:"uid,omitempty"`
+	Title        string            `json:"title"`
+	FolderUID    string            `json:"folderUID"`
+	RuleGroup    string            `json:"ruleGroup"`
+	Condition    string            `json:"condition"`
+	Data         []AlertQuery      `json:"data"`
+	NoDataState  string            `json:"noDataState"`
+	ExecErrState string            `json:"execErrState"`
+	For          string            `json:"for"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+}
+
+// An AlertQuery is a query or expression of an alert rule, Model being the datasource query json.
+// The time range is given in seconds before now, e.g. From 600 and To 0 for the last 10 minutes.
+type AlertQuery struct {
+	RefID             string                 `json:"refId"`
+	DatasourceUID     string                 `json:"datasourceUid"`
+	RelativeTimeRange RelativeTimeRange      `json:"relativeTimeRange"`
+	Model             map[string]interface{} `json:"model"`
+}
+
+type RelativeTimeRange struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// defaultAlertStates sets the empty NoDataState and ExecErrState to NoData and Error.
+func defaultAlertStates(rule AlertRule) AlertRule {
+	if rule.NoDataState == "" {
+		rule.NoDataState = AlertStateNoData
+	}
+	if rule.ExecErrState == "" {
+		rule.ExecErrState = AlertStateError
+	}
+	return rule
+}
+
+// validateAlertRule checks the states of the rule and that its condition is one of its queries.
+func validateAlertRule(rule AlertRule) error {
+	if rule.NoDataState != AlertStateAlerting && rule.NoDataState != AlertStateNoData && rule.NoDataState != AlertStateOK {
+		return GrafanaError{0, fmt.Sprintf("alert rule %q has invalid noDataState %q", rule.Title, rule.NoDataState)}
+	}
+	if rule.ExecErrState != AlertStateAlerting && rule.ExecErrState != AlertStateError && rule.ExecErrState != AlertStateOK {
+		return GrafanaError{0, fmt.Sprintf("alert rule %q has invalid execErrState %q", rule.Title, rule.ExecErrState)}
+	}
+	for _, q := range rule.Data {
+		if q.RefID == rule.Condition {
+			return nil
+		}
+	}
+	return GrafanaError{0, fmt.Sprintf("alert rule %q condition %q matches no query", rule.Title, rule.Condition)}
+}
+
+// CreateAlertRule creates the alert rule through the provisioning API and returns its uid.
+// Empty NoDataState and ExecErrState default to NoData and Error.
+func (s *Session) CreateAlertRule(rule AlertRule) (uid string, err error) {
+	rule = defaultAlertStates(rule)
+	if err = validateAlertRule(rule); err != nil {
+		return
+	}
+	reqURL := s.url + "/api/v1/provisioning/alert-rules"
+	jsonStr, _ := json.Marshal(rule)
+	body, err := s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	if err != nil {
+		return
+	}
+	var res AlertRule
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&res)
+	return res.UID, err
+}
+
+// alertEvaluationStep is the base interval of the alert scheduler, group intervals must be a multiple of it.
+const alertEvaluationStep = 10 * time.Second
+
+// SetAlertRuleGroup replaces the rules of the group in the folder, all evaluated every interval.
+// The rules are moved to the folder and group, and interval must be a multiple of 10 seconds.
+func (s *Session) SetAlertRuleGroup(folderUID, groupName string, interval time.Duration, rules []AlertRule) (err error) {
+	if interval < alertEvaluationStep || interval%alertEvaluationStep != 0 {
+		return GrafanaError{0, fmt.Sprintf("alert rule group interval %s is not a multiple of %s", interval, alertEvaluationStep)}
+	}
+	groupRules := make([]AlertRule, 0, len(rules))
+	for _, rule := range rules {
+		rule = defaultAlertStates(rule)
+		rule.FolderUID = folderUID
+		rule.RuleGroup = groupName
+		if err = validateAlertRule(rule); err != nil {
+			return
+		}
+		groupRules = append(groupRules, rule)
+	}
+	group := map[string]interface{}{
+		"title":     groupName,
+		"folderUid": folderUID,
+		"interval":  int(interval / time.Second),
+		"rules":     groupRules,
+	}
+	reqURL := fmt.Sprintf("%s/api/v1/provisioning/folder/%s/rule-groups/%s", s.url, url.PathEscape(folderUID), url.PathEscape(groupName))
+	jsonStr, _ := json.Marshal(group)
+	_, err = s.httpRequest("PUT", reqURL, bytes.NewBuffer(jsonStr))
+	return
+}
+
+// A MuteTiming silences the notifications of the policies using it during its time intervals,
+// e.g. maintenance windows
+type MuteTiming struct {
+	Name          string         `json:"name"`
+	TimeIntervals []TimeInterval `json:"time_intervals"`
+}
+
+// A TimeInterval matches when all its non empty fields match, e.g. Weekdays "saturday:sunday".
+// Ranges are written "start:end", times in 24h format like "22:00".
+type TimeInterval struct {
+	Times       []TimeOfDayRange `json:"times,omitempty"`
+	Weekdays    []string         `json:"weekdays,omitempty"`
+	DaysOfMonth []string         `json:"days_of_month,omitempty"`
+	Months      []string         `json:"months,omitempty"`
+	Years       []string         `json:"years,omitempty"`
+	Location    string           `json:"location,omitempty"`
+}
+
+type TimeOfDayRange struct {
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// CreateMuteTiming creates the mute timing through the provisioning API.
+func (s *Session) CreateMuteTiming(mt MuteTiming) (err error) {
+	if mt.Name == "" {
+		return GrafanaError{0, "mute timing name is empty"}
+	}
+	reqURL := s.url + "/api/v1/provisioning/mute-timings"
+	jsonStr, _ := json.Marshal(mt)
+	_, err = s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	return
+}
+
+// A NotificationPolicy routes the alerts matching its matchers to a contact point, Receiver.
+// ObjectMatchers are [label, operator, value] triples like ["team", "=", "db"].
+// The root policy matches every alert and its Routes are the nested policies.
+type NotificationPolicy struct {
+	Receiver          string               `json:"receiver,omitempty"`
+	GroupBy           []string             `json:"group_by,omitempty"`
+	ObjectMatchers    [][]string           `json:"object_matchers,omitempty"`
+	MuteTimeIntervals []string             `json:"mute_time_intervals,omitempty"`
+	Continue          bool                 `json:"continue,omitempty"`
+	GroupWait         string               `json:"group_wait,omitempty"`
+	GroupInterval     string               `json:"group_interval,omitempty"`
+	RepeatInterval    string               `json:"repeat_interval,omitempty"`
+	Routes            []NotificationPolicy `json:"routes,omitempty"`
+}
+
+// SetNotificationPolicy replaces the whole notification policy tree, tree being the root policy.
+func (s *Session) SetNotificationPolicy(tree NotificationPolicy) (err error) {
+	if tree.Receiver == "" {
+		return GrafanaError{0, "root notification policy needs a receiver"}
+	}
+	reqURL := s.url + "/api/v1/provisioning/policies"
+	jsonStr, _ := json.Marshal(tree)
+	_, err = s.httpRequest("PUT", reqURL, bytes.NewBuffer(jsonStr))
+	return
+}
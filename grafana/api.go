@@ -2,20 +2,27 @@ package grafana
 
 import (
 	"bytes"
+	"crypto/sha1"
 	"crypto/tls"
+	"encoding/base32"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/cookiejar"
 	"regexp"
+	"strings"
+	"sync"
 	"time"
 )
 
 const timeout = 5
 
 var protocolRegexp = regexp.MustCompile(`^https://`)
+var nonAlphaNumRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+var uidRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,40}$`)
 
 // GrafanaError is a error structure to handle error messages in this library
 type GrafanaError struct {
@@ -25,7 +32,70 @@ type GrafanaError struct {
 
 // A GrafanaMessage contains the json error message received when http request failed
 type GrafanaMessage struct {
-	Message string `json:"message"`
+	Message string         `json:"message"`
+	TraceID string         `json:"traceID,omitempty"`
+	Status  string         `json:"status,omitempty"`
+	Data    GrafanaErrData `json:"data,omitempty"`
+	Errors  []FieldError   `json:"errors,omitempty"`
+}
+
+// GrafanaErrData contains the nested details some endpoints return, e.g. datasource validation
+type GrafanaErrData struct {
+	ValidationMessage string `json:"validationMessage,omitempty"`
+}
+
+// A FieldError is a binding error Grafana returns when a request field is invalid
+type FieldError struct {
+	FieldNames     []string `json:"fieldNames"`
+	Classification string   `json:"classification"`
+	Message        string   `json:"message"`
+}
+
+// parseGrafanaMessage decodes an error body, which is either an object or a bare list of field errors.
+// Object fields are decoded one by one, so that one of an unexpected type, e.g. a numeric status,
+// doesn't lose the message.
+func parseGrafanaMessage(data []byte) GrafanaMessage {
+	var gMess GrafanaMessage
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		var fieldErrs []FieldError
+		if json.Unmarshal(data, &fieldErrs) == nil {
+			gMess.Errors = fieldErrs
+		} else {
+			gMess.Message = strings.TrimSpace(string(data))
+		}
+		return gMess
+	}
+	json.Unmarshal(fields["message"], &gMess.Message)
+	json.Unmarshal(fields["traceID"], &gMess.TraceID)
+	var status interface{}
+	if json.Unmarshal(fields["status"], &status) == nil && status != nil {
+		gMess.Status = fmt.Sprint(status)
+	}
+	json.Unmarshal(fields["data"], &gMess.Data)
+	json.Unmarshal(fields["errors"], &gMess.Errors)
+	return gMess
+}
+
+// Description joins the message with every detail Grafana provided.
+func (m GrafanaMessage) Description() string {
+	parts := make([]string, 0)
+	if m.Message != "" {
+		parts = append(parts, m.Message)
+	}
+	if m.Data.ValidationMessage != "" {
+		parts = append(parts, "validation: "+m.Data.ValidationMessage)
+	}
+	for _, fe := range m.Errors {
+		parts = append(parts, fmt.Sprintf("%s %s: %s", strings.Join(fe.FieldNames, ","), fe.Classification, fe.Message))
+	}
+	if m.Status != "" {
+		parts = append(parts, "status: "+m.Status)
+	}
+	if m.TraceID != "" {
+		parts = append(parts, "traceID: "+m.TraceID)
+	}
+	return strings.Join(parts, "; ")
 }
 
 // Error generate a text error message.
@@ -42,10 +112,35 @@ type DashboardResult struct {
 	Model Dashboard `json:"model"`
 }
 
+// UnmarshalJSON accepts the dashboard under "model" as old Grafana sends it, or under "dashboard".
+func (d *DashboardResult) UnmarshalJSON(data []byte) error {
+	var res struct {
+		Meta      Meta       `json:"meta"`
+		Model     *Dashboard `json:"model"`
+		Dashboard *Dashboard `json:"dashboard"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	d.Meta = res.Meta
+	if res.Dashboard != nil {
+		d.Model = *res.Dashboard
+	} else if res.Model != nil {
+		d.Model = *res.Model
+	}
+	return nil
+}
+
 // A Meta contains a Dashboard metadata.
 type Meta struct {
+	CanAdmin   bool   `json:"canAdmin"`
+	CanEdit    bool   `json:"canEdit"`
+	CanSave    bool   `json:"canSave"`
+	CanStar    bool   `json:"canStar"`
 	Created    string `json:"created"`
 	Expires    string `json:"expires"`
+	FolderID   int    `json:"folderId"`
+	FolderUID  string `json:"folderUid"`
 	IsHome     bool   `json:"isHome"`
 	IsSnapshot bool   `json:"isSnapshot"`
 	IsStarred  bool   `json:"isStarred"`
@@ -54,22 +149,27 @@ type Meta struct {
 
 // A Dashboard contains the Dashboard structure.
 type Dashboard struct {
-	Editable      bool          `json:"editable"`
-	GnetID        interface{}   `json:"gnetId"`
-	GraphTooltip  int           `json:"graphTooltip"`
-	HideControls  bool          `json:"hideControls"`
-	ID            int           `json:"id"`
-	Links         []interface{} `json:"links"`
-	Rows          []Row         `json:"rows"`
-	SchemaVersion int           `json:"schemaVersion"`
-	Style         string        `json:"style"`
-	Tags          []interface{} `json:"tags"`
-	Templating    Templating    `json:"templating"`
-	Time          Time          `json:"time"`
-	Timepicker    Timepicker    `json:"timepicker"`
-	Timezone      string        `json:"timezone"`
-	Title         string        `json:"title"`
-	Version       int           `json:"version"`
+	Editable             bool          `json:"editable"`
+	FiscalYearStartMonth int           `json:"fiscalYearStartMonth,omitempty"`
+	GnetID               interface{}   `json:"gnetId"`
+	GraphTooltip         int           `json:"graphTooltip"`
+	HideControls         bool          `json:"hideControls"`
+	ID                   int           `json:"id"`
+	Links                []interface{} `json:"links"`
+	LiveNow              bool          `json:"liveNow,omitempty"`
+	Panels               []Panel       `json:"panels,omitempty"`
+	Rows                 []Row         `json:"rows"`
+	SchemaVersion        int           `json:"schemaVersion"`
+	Style                string        `json:"style"`
+	Tags                 []interface{} `json:"tags"`
+	Templating           Templating    `json:"templating"`
+	Time                 Time          `json:"time"`
+	Timepicker           Timepicker    `json:"timepicker"`
+	Timezone             string        `json:"timezone"`
+	Title                string        `json:"title"`
+	UID                  string        `json:"uid,omitempty"`
+	Version              int           `json:"version"`
+	WeekStart            string        `json:"weekStart,omitempty"`
 }
 
 type Templating struct {
@@ -83,8 +183,8 @@ func GetDefaultTemplating(tagNames []string, measurementName, datasource string)
 }
 
 type Time struct {
-	From string `json:"from"`
-	To   string `json:"to"`
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
 }
 type Timepicker struct {
 	RefreshIntervals []string `json:"refresh_intervals"`
@@ -111,6 +211,147 @@ func GetDefaultDashBoard(dashboardTitle string) *Dashboard {
 	return db
 }
 
+// DashboardOptions groups the dashboard wide settings applied when creating a dashboard.
+type DashboardOptions struct {
+	Editable     bool
+	GraphTooltip int
+	HideControls bool
+}
+
+func GetDefaultDashboardOptions() DashboardOptions {
+	return DashboardOptions{
+		Editable:     true,
+		GraphTooltip: 0,
+		HideControls: false,
+	}
+}
+
+// GetDashboardOptions returns the dashboard wide settings of db.
+func GetDashboardOptions(db Dashboard) DashboardOptions {
+	return DashboardOptions{
+		Editable:     db.Editable,
+		GraphTooltip: db.GraphTooltip,
+		HideControls: db.HideControls,
+	}
+}
+
+// ApplyDashboardOptions sets the dashboard wide settings of db.
+func ApplyDashboardOptions(db Dashboard, opts DashboardOptions) Dashboard {
+	db = SetDashboardEditable(db, opts.Editable)
+	db = SetGraphTooltip(db, opts.GraphTooltip)
+	db = SetHideControls(db, opts.HideControls)
+	return db
+}
+
+// SetDashboardEditable locks the dashboard against edits in the UI when editable is false.
+func SetDashboardEditable(db Dashboard, editable bool) Dashboard {
+	db.Editable = editable
+	return db
+}
+
+// SetGraphTooltip sets the crosshair sharing between panels: 0 default, 1 shared crosshair, 2 shared tooltip.
+func SetGraphTooltip(db Dashboard, mode int) Dashboard {
+	db.GraphTooltip = mode
+	return db
+}
+
+// SetHideControls hides the time picker and variable controls, e.g. for dashboards embedded in a portal.
+func SetHideControls(db Dashboard, hide bool) Dashboard {
+	db.HideControls = hide
+	return db
+}
+
+// ValidTimezone reports whether tz is "browser", "utc" or an IANA zone like "America/New_York".
+func ValidTimezone(tz string) bool {
+	if tz == "browser" || tz == "utc" {
+		return true
+	}
+	if tz == "" || tz == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
+}
+
+// SetDashboardTimezone pins the dashboard to a timezone instead of the viewer's browser one.
+// The dashboard is returned unchanged if tz isn't valid, see ValidTimezone.
+func SetDashboardTimezone(db Dashboard, tz string) Dashboard {
+	if ValidTimezone(tz) {
+		db.Timezone = tz
+	}
+	return db
+}
+
+// SetLiveNow makes Grafana continuously redraw time series panels as new data arrives.
+func SetLiveNow(db Dashboard, liveNow bool) Dashboard {
+	db.LiveNow = liveNow
+	return db
+}
+
+// validWeekStart reports whether day is a first day of the week Grafana accepts, empty meaning the browser default.
+func validWeekStart(day string) bool {
+	return day == "" || day == "monday" || day == "sunday" || day == "saturday"
+}
+
+// SetWeekStart sets the first day of the week used by time ranges like "this week": "monday", "sunday"
+// or "saturday", an empty day using the browser default. The dashboard is returned unchanged if day isn't valid.
+func SetWeekStart(db Dashboard, day string) Dashboard {
+	if validWeekStart(day) {
+		db.WeekStart = day
+	}
+	return db
+}
+
+// SetFiscalYearStart sets the month the fiscal year starts with, 0 being January and 3 April.
+// The dashboard is returned unchanged if month isn't between 0 and 11.
+func SetFiscalYearStart(db Dashboard, month int) Dashboard {
+	if month >= 0 && month <= 11 {
+		db.FiscalYearStartMonth = month
+	}
+	return db
+}
+
+// ValidUID reports whether uid is accepted by Grafana: 1 to 40 letters, digits, '-' or '_'.
+func ValidUID(uid string) bool {
+	return uidRegexp.MatchString(uid)
+}
+
+// SetDashboardUID sets the uid the dashboard is addressed by in urls and the API.
+// The dashboard is returned unchanged if uid isn't valid, see ValidUID.
+func SetDashboardUID(db Dashboard, uid string) Dashboard {
+	if ValidUID(uid) {
+		db.UID = uid
+	}
+	return db
+}
+
+// StableUID derives a valid uid from the title, the same on every run, so provisioning is idempotent
+// without storing a title to uid mapping. It is the lowercase base32 sha1 of the title, 32 characters long.
+func StableUID(title string) string {
+	sum := sha1.Sum([]byte(title))
+	return strings.ToLower(base32.StdEncoding.EncodeToString(sum[:]))
+}
+
+// MarshalDashboardCanonical returns a deterministic indented json of the dashboard.
+// Every object, including free-form interface{} values, has its keys sorted so output is stable across runs.
+func MarshalDashboardCanonical(db Dashboard) ([]byte, error) {
+	raw, err := json.Marshal(db)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var generic interface{}
+	if err = dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	res, err := json.MarshalIndent(generic, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(res, '\n'), nil
+}
+
 type Row struct {
 	Collapse        bool        `json:"collapse"`
 	Height          string      `json:"height"`
@@ -141,34 +382,125 @@ func GetDefaultRow(panelTitle string, influxql string) Row {
 	return row
 }
 
+// SetRowRepeat repeats the row for every selected value of the template variable, e.g. "region" or "$region",
+// each copy having its panels scoped to one value. An empty variable stops repeating the row.
+// RepeatIteration and RepeatRowID mark the copies Grafana generates, so they are cleared on the source row.
+func SetRowRepeat(row Row, variable string) Row {
+	variable = strings.TrimPrefix(variable, "$")
+	row.Repeat = nil
+	if variable != "" {
+		row.Repeat = variable
+	}
+	row.RepeatIteration = nil
+	row.RepeatRowID = nil
+	return row
+}
+
+// rowSpan is the number of grid columns in a row.
+const rowSpan = 12
+
+// SetRowPanelWeights distributes the 12 columns of a row over its panels proportionally to weights.
+// Spans are rounded by largest remainder so that they always sum to 12.
+// Every panel gets at least one column, taken from the widest panels.
+// The row is returned unchanged if weights doesn't match the panel count, has a weight that isn't positive
+// or more than 12 entries.
+func SetRowPanelWeights(row Row, weights []int) Row {
+	if len(weights) != len(row.Panels) || len(weights) == 0 || len(weights) > rowSpan {
+		return row
+	}
+	total := 0
+	for _, w := range weights {
+		if w <= 0 {
+			return row
+		}
+		total += w
+	}
+	spans := make([]int, len(weights))
+	remainders := make([]int, len(weights))
+	used := 0
+	for i, w := range weights {
+		spans[i] = w * rowSpan / total
+		remainders[i] = w * rowSpan % total
+		used += spans[i]
+	}
+	for ; used < rowSpan; used++ {
+		best := 0
+		for i := range remainders {
+			if remainders[i] > remainders[best] {
+				best = i
+			}
+		}
+		spans[best]++
+		remainders[best] = -1
+	}
+	for i := range spans {
+		if spans[i] > 0 {
+			continue
+		}
+		widest := 0
+		for j := range spans {
+			if spans[j] > spans[widest] {
+				widest = j
+			}
+		}
+		spans[widest]--
+		spans[i]++
+	}
+	panels := make([]Panel, len(row.Panels))
+	copy(panels, row.Panels)
+	for i := range panels {
+		panels[i].Span = spans[i]
+	}
+	row.Panels = panels
+	return row
+}
+
 type Panel struct {
-	AliasColors     struct{}      `json:"aliasColors"`
-	Bars            bool          `json:"bars"`
-	Datasource      interface{}   `json:"datasource"`
-	Fill            int           `json:"fill"`
-	ID              int           `json:"id"`
-	Legend          Legend        `json:"legend"`
-	Lines           bool          `json:"lines"`
-	Linewidth       int           `json:"linewidth"`
-	Links           []interface{} `json:"links"`
-	NullPointMode   string        `json:"nullPointMode"`
-	Percentage      bool          `json:"percentage"`
-	Pointradius     int           `json:"pointradius"`
-	Points          bool          `json:"points"`
-	Renderer        string        `json:"renderer"`
-	SeriesOverrides []interface{} `json:"seriesOverrides"`
-	Span            int           `json:"span"`
-	Stack           bool          `json:"stack"`
-	SteppedLine     bool          `json:"steppedLine"`
-	Targets         []Target      `json:"targets"`
-	Thresholds      []interface{} `json:"thresholds"`
-	TimeFrom        interface{}   `json:"timeFrom"`
-	TimeShift       interface{}   `json:"timeShift"`
-	Title           string        `json:"title"`
-	Tooltip         Tooltip       `json:"tooltip"`
-	Type            string        `json:"type"`
-	Xaxis           Xaxis         `json:"xaxis"`
-	Yaxes           []Yaxes       `json:"yaxes"`
+	AliasColors      struct{}                   `json:"aliasColors"`
+	Bars             bool                       `json:"bars"`
+	CacheTimeout     string                     `json:"cacheTimeout,omitempty"`
+	Collapsed        bool                       `json:"collapsed,omitempty"`
+	Color            *HeatmapColor              `json:"color,omitempty"`
+	DataFormat       string                     `json:"dataFormat,omitempty"`
+	Datasource       interface{}                `json:"datasource"`
+	Description      string                     `json:"description,omitempty"`
+	FieldConfig      *FieldConfig               `json:"fieldConfig,omitempty"`
+	Fill             int                        `json:"fill"`
+	GridPos          *GridPos                   `json:"gridPos,omitempty"`
+	HideTimeOverride bool                       `json:"hideTimeOverride,omitempty"`
+	HideZeroBuckets  bool                       `json:"hideZeroBuckets,omitempty"`
+	ID               int                        `json:"id"`
+	Interval         string                     `json:"interval,omitempty"`
+	Legend           Legend                     `json:"legend"`
+	LibraryPanel     *LibraryRef                `json:"libraryPanel,omitempty"`
+	Lines            bool                       `json:"lines"`
+	Linewidth        int                        `json:"linewidth"`
+	Links            []interface{}              `json:"links"`
+	MaxDataPoints    int                        `json:"maxDataPoints,omitempty"`
+	MaxPerRow        int                        `json:"maxPerRow,omitempty"`
+	NullPointMode    string                     `json:"nullPointMode"`
+	Options          map[string]json.RawMessage `json:"options,omitempty"`
+	Panels           []Panel                    `json:"panels,omitempty"`
+	Percentage       bool                       `json:"percentage"`
+	Pointradius      int                        `json:"pointradius"`
+	Points           bool                       `json:"points"`
+	Renderer         string                     `json:"renderer"`
+	Repeat           string                     `json:"repeat,omitempty"`
+	RepeatDirection  string                     `json:"repeatDirection,omitempty"`
+	SeriesOverrides  []interface{}              `json:"seriesOverrides"`
+	Span             int                        `json:"span"`
+	Stack            bool                       `json:"stack"`
+	SteppedLine      bool                       `json:"steppedLine"`
+	Targets          []Target                   `json:"targets"`
+	Thresholds       []interface{}              `json:"thresholds"`
+	TimeFrom         interface{}                `json:"timeFrom"`
+	TimeShift        interface{}                `json:"timeShift"`
+	Title            string                     `json:"title"`
+	Tooltip          Tooltip                    `json:"tooltip"`
+	Transparent      bool                       `json:"transparent,omitempty"`
+	Type             string                     `json:"type"`
+	Xaxis            Xaxis                      `json:"xaxis"`
+	Yaxes            []Yaxes                    `json:"yaxes"`
 }
 
 func GetDefaultPanel(title string, influxql string) Panel {
@@ -180,7 +512,7 @@ func GetDefaultPanel(title string, influxql string) Panel {
 	panel.Lines = true
 	panel.Linewidth = 1
 	panel.Links = make([]interface{}, 0)
-	panel.NullPointMode = "null"
+	panel.NullPointMode = string(NullPointModeNull)
 	panel.Percentage = false
 	panel.Pointradius = 5
 	panel.Points = false
@@ -201,14 +533,230 @@ func GetDefaultPanel(title string, influxql string) Panel {
 	return panel
 }
 
+// SetPanelTransparent removes the panel background, e.g. for dashboards embedded in another page.
+func SetPanelTransparent(p Panel, transparent bool) Panel {
+	p.Transparent = transparent
+	return p
+}
+
+// SetPanelPoints shows or hides the data points with the given radius, e.g. for sparse scatter data.
+// A radius below 1 keeps the current one.
+func SetPanelPoints(p Panel, show bool, radius int) Panel {
+	p.Points = show
+	if radius > 0 {
+		p.Pointradius = radius
+	}
+	return p
+}
+
+// SetPanelSteppedLine draws lines as steps instead of interpolating between points, e.g. for state metrics.
+func SetPanelSteppedLine(p Panel, stepped bool) Panel {
+	p.SteppedLine = stepped
+	return p
+}
+
+// SetPanelMaxDataPoints caps the number of points the panel queries return, 0 lets Grafana decide.
+func SetPanelMaxDataPoints(p Panel, n int) Panel {
+	p.MaxDataPoints = n
+	return p
+}
+
+// SetPanelCacheTimeout makes Grafana cache the panel query results, e.g. "60" seconds or "5m".
+func SetPanelCacheTimeout(p Panel, timeout string) Panel {
+	p.CacheTimeout = timeout
+	return p
+}
+
+// SetPanelTimeOverride makes the panel show a relative time window like "30d" and/or shift it like "1w",
+// independently of the dashboard time. An empty string leaves that part unset.
+// hide removes the override label from the panel header.
+func SetPanelTimeOverride(p Panel, timeFrom, timeShift string, hide bool) Panel {
+	p.TimeFrom = nil
+	if timeFrom != "" {
+		p.TimeFrom = timeFrom
+	}
+	p.TimeShift = nil
+	if timeShift != "" {
+		p.TimeShift = timeShift
+	}
+	p.HideTimeOverride = hide
+	return p
+}
+
+// SetPanelRepeat repeats the panel for every value of the template variable.
+// direction is "h" to tile horizontally, wrapping after maxPerRow panels, or "v" to stack them;
+// any other direction falls back to "h".
+func SetPanelRepeat(p Panel, variable, direction string, maxPerRow int) Panel {
+	if direction != "v" {
+		direction = "h"
+	}
+	p.Repeat = variable
+	p.RepeatDirection = direction
+	p.MaxPerRow = 0
+	if direction == "h" {
+		p.MaxPerRow = maxPerRow
+	}
+	return p
+}
+
+// SetPanelOption sets an entry of the options object modern panels keep most of their settings in,
+// e.g. "legend" or "reduceOptions". The panel is returned unchanged if value can't be marshaled to json.
+func SetPanelOption(p Panel, key string, value interface{}) Panel {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return p
+	}
+	options := make(map[string]json.RawMessage, len(p.Options)+1)
+	for k, v := range p.Options {
+		options[k] = v
+	}
+	options[key] = raw
+	p.Options = options
+	return p
+}
+
+// A FieldConfig contains the field options of a panel, applied to every field unless overridden
+type FieldConfig struct {
+	Defaults  FieldDefaults `json:"defaults"`
+	Overrides []interface{} `json:"overrides"`
+}
+
+type FieldDefaults struct {
+	Custom     *FieldCustom `json:"custom,omitempty"`
+	Links      []DataLink   `json:"links,omitempty"`
+	Thresholds *Thresholds  `json:"thresholds,omitempty"`
+	Unit       string       `json:"unit,omitempty"`
+}
+
+// Thresholds color the values of modern panels, each step applying from its value up to the next one
+type Thresholds struct {
+	Mode  string          `json:"mode"`
+	Steps []ThresholdStep `json:"steps"`
+}
+
+// A ThresholdStep is a color from Value, the first step having a nil Value to cover everything below
+type ThresholdStep struct {
+	Color string   `json:"color"`
+	Value *float64 `json:"value"`
+}
+
+// A FieldCustom contains the drawing options of a timeseries panel
+type FieldCustom struct {
+	FillOpacity       int    `json:"fillOpacity"`
+	GradientMode      string `json:"gradientMode"`
+	LineInterpolation string `json:"lineInterpolation"`
+	LineWidth         int    `json:"lineWidth"`
+	SpanNulls         bool   `json:"spanNulls"`
+}
+
+// A DataLink is a link shown on the data points of a panel, e.g. to the matching traces
+type DataLink struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	TargetBlank bool   `json:"targetBlank,omitempty"`
+}
+
+// getFieldConfig returns a copy of the panel field config so setters don't modify the caller's panel.
+func getFieldConfig(p Panel) FieldConfig {
+	if p.FieldConfig == nil {
+		return FieldConfig{Overrides: make([]interface{}, 0)}
+	}
+	return *p.FieldConfig
+}
+
+// AddDataLink adds a link to the data points of the panel.
+// url may use Grafana variables like ${__value.raw} or ${__field.labels.traceID}.
+func AddDataLink(p Panel, title, url string) Panel {
+	fc := getFieldConfig(p)
+	links := make([]DataLink, 0, len(fc.Defaults.Links)+1)
+	links = append(links, fc.Defaults.Links...)
+	fc.Defaults.Links = append(links, DataLink{Title: title, URL: url})
+	p.FieldConfig = &fc
+	return p
+}
+
+// GetTimeSeriesPanel returns a timeseries panel, the successor of the graph panel since Grafana 8.
+// Lines are linear with a light fill and gaps where values are null.
+func GetTimeSeriesPanel(title string, influxql string) Panel {
+	panel := GetDefaultPanel(title, influxql)
+	panel.Type = "timeseries"
+	panel.FieldConfig = &FieldConfig{
+		Defaults: FieldDefaults{Custom: &FieldCustom{
+			FillOpacity:       10,
+			GradientMode:      "none",
+			LineInterpolation: "linear",
+			LineWidth:         1,
+			SpanNulls:         false,
+		}},
+		Overrides: make([]interface{}, 0),
+	}
+	return panel
+}
+
+// getFieldCustom returns a copy of the timeseries options of the panel field config.
+func getFieldCustom(fc FieldConfig) FieldCustom {
+	if fc.Defaults.Custom == nil {
+		return FieldCustom{GradientMode: "none", LineInterpolation: "linear", LineWidth: 1}
+	}
+	return *fc.Defaults.Custom
+}
+
+// SetPanelLineInterpolation sets how a timeseries panel draws lines between points:
+// "linear", "smooth", "stepBefore" or "stepAfter". The panel is returned unchanged if mode isn't valid.
+func SetPanelLineInterpolation(p Panel, mode string) Panel {
+	if mode != "linear" && mode != "smooth" && mode != "stepBefore" && mode != "stepAfter" {
+		return p
+	}
+	fc := getFieldConfig(p)
+	custom := getFieldCustom(fc)
+	custom.LineInterpolation = mode
+	fc.Defaults.Custom = &custom
+	p.FieldConfig = &fc
+	return p
+}
+
+// SetPanelFillGradient sets the fill of a timeseries panel, opacity going from 0 to 100 and gradient being
+// "none", "opacity", "hue" or "scheme". The panel is returned unchanged if either isn't valid.
+func SetPanelFillGradient(p Panel, opacity int, gradient string) Panel {
+	if opacity < 0 || opacity > 100 {
+		return p
+	}
+	if gradient != "none" && gradient != "opacity" && gradient != "hue" && gradient != "scheme" {
+		return p
+	}
+	fc := getFieldConfig(p)
+	custom := getFieldCustom(fc)
+	custom.FillOpacity = opacity
+	custom.GradientMode = gradient
+	fc.Defaults.Custom = &custom
+	p.FieldConfig = &fc
+	return p
+}
+
+// SetPanelSpanNulls makes a timeseries panel connect the points around null values instead of leaving gaps,
+// like the "connected" null point mode of the graph panel.
+func SetPanelSpanNulls(p Panel, span bool) Panel {
+	fc := getFieldConfig(p)
+	custom := getFieldCustom(fc)
+	custom.SpanNulls = span
+	fc.Defaults.Custom = &custom
+	p.FieldConfig = &fc
+	return p
+}
+
 type Legend struct {
-	Avg     bool `json:"avg"`
-	Current bool `json:"current"`
-	Max     bool `json:"max"`
-	Min     bool `json:"min"`
-	Show    bool `json:"show"`
-	Total   bool `json:"total"`
-	Values  bool `json:"values"`
+	AsTable   bool   `json:"alignAsTable,omitempty"`
+	Avg       bool   `json:"avg"`
+	Current   bool   `json:"current"`
+	Max       bool   `json:"max"`
+	Min       bool   `json:"min"`
+	RightSide bool   `json:"rightSide,omitempty"`
+	Show      bool   `json:"show"`
+	Sort      string `json:"sort,omitempty"`
+	SortDesc  bool   `json:"sortDesc,omitempty"`
+	Total     bool   `json:"total"`
+	Values    bool   `json:"values"`
+	Width     int    `json:"sideWidth,omitempty"`
 }
 
 func GetDefaultLegend() Legend {
@@ -223,18 +771,66 @@ func GetDefaultLegend() Legend {
 	}
 }
 
+// SetPanelLegendRightSide moves the legend to the right of the graph instead of below it.
+// width is the legend width in pixels, 0 lets Grafana size it.
+func SetPanelLegendRightSide(p Panel, rightSide bool, width int) Panel {
+	p.Legend.RightSide = rightSide
+	p.Legend.Width = 0
+	if rightSide && width > 0 {
+		p.Legend.Width = width
+	}
+	return p
+}
+
+// SetPanelLegendTable shows the legend as a table with one column per displayed value.
+func SetPanelLegendTable(p Panel, asTable bool) Panel {
+	p.Legend.AsTable = asTable
+	return p
+}
+
+// SetPanelLegendSort sorts the legend by one of "avg", "current", "max", "min" or "total",
+// which is shown in the legend since Grafana only sorts by displayed values.
+// An empty by removes the sort. The panel is returned unchanged if by isn't valid.
+func SetPanelLegendSort(p Panel, by string, desc bool) Panel {
+	switch by {
+	case "":
+	case "avg":
+		p.Legend.Avg = true
+	case "current":
+		p.Legend.Current = true
+	case "max":
+		p.Legend.Max = true
+	case "min":
+		p.Legend.Min = true
+	case "total":
+		p.Legend.Total = true
+	default:
+		return p
+	}
+	if by != "" {
+		p.Legend.Values = true
+	}
+	p.Legend.Sort = by
+	p.Legend.SortDesc = by != "" && desc
+	return p
+}
+
 type Target struct {
-	DsType  string `json:"dsType"`
-	GroupBy []struct {
+	Datasource interface{} `json:"datasource,omitempty"`
+	DsType     string      `json:"dsType"`
+	GroupBy    []struct {
 		Params []string `json:"params"`
 		Type   string   `json:"type"`
 	} `json:"groupBy"`
+	Interval     string `json:"interval,omitempty"`
 	Measurement  string `json:"measurement"`
+	PanelID      int    `json:"panelId,omitempty"`
 	Policy       string `json:"policy"`
 	Query        string `json:"query"`
 	RawQuery     bool   `json:"rawQuery"`
 	RefID        string `json:"refId"`
 	ResultFormat string `json:"resultFormat"`
+	ScenarioID   string `json:"scenarioId,omitempty"`
 	Select       [][]struct {
 		Params []string `json:"params"`
 		Type   string   `json:"type"`
@@ -250,11 +846,75 @@ func GetDefaultTargets(influxql string) []Target {
 	targets.Query = influxql
 	targets.RawQuery = true
 	targets.RefID = "A"
-	targets.ResultFormat = "time_series"
+	targets.ResultFormat = ResultFormatTimeSeries
 	res = append(res, targets)
 	return res
 }
 
+// GetFluxTarget returns a target for an InfluxDB 2.x datasource queried with Flux.
+// Flux queries carry the bucket and range themselves so policy and measurement are left empty.
+func GetFluxTarget(fluxQuery string) Target {
+	target := Target{}
+	target.DsType = "influxdb"
+	target.Query = fluxQuery
+	target.RefID = "A"
+	target.ResultFormat = ResultFormatTimeSeries
+	return target
+}
+
+// refIDForIndex returns the refId Grafana gives to the target at index i: A to Z, then AA, AB...
+func refIDForIndex(i int) string {
+	id := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		id = string(rune('A'+(i-1)%26)) + id
+	}
+	return id
+}
+
+// AddTargetToPanel appends a target to the panel. Its refId is derived from its position
+// so that regenerating a panel always gives the same json.
+func AddTargetToPanel(p Panel, t Target) Panel {
+	targets := make([]Target, 0, len(p.Targets)+1)
+	targets = append(targets, p.Targets...)
+	t.RefID = refIDForIndex(len(targets))
+	p.Targets = append(targets, t)
+	return p
+}
+
+// SetTargetRefID sets the refId of the target, it must be unique within its panel.
+func SetTargetRefID(t Target, refID string) Target {
+	t.RefID = refID
+	return t
+}
+
+// Formats of the series returned by an InfluxDB target
+const (
+	ResultFormatTimeSeries = "time_series"
+	ResultFormatTable      = "table"
+	ResultFormatLogs       = "logs"
+)
+
+// SetTargetResultFormat sets how the target results are returned, e.g. ResultFormatTable for table panels
+// which otherwise show one row per series. The target is returned unchanged if format isn't valid.
+func SetTargetResultFormat(t Target, format string) Target {
+	if format == ResultFormatTimeSeries || format == ResultFormatTable || format == ResultFormatLogs {
+		t.ResultFormat = format
+	}
+	return t
+}
+
+// SetTargetInterval sets the minimum group by interval of the target, e.g. "30s" to match the collection frequency.
+func SetTargetInterval(t Target, interval string) Target {
+	t.Interval = interval
+	return t
+}
+
+// SetPanelInterval sets the minimum group by interval used by every query of the panel.
+func SetPanelInterval(p Panel, interval string) Panel {
+	p.Interval = interval
+	return p
+}
+
 type Tooltip struct {
 	Shared    bool   `json:"shared"`
 	Sort      int    `json:"sort"`
@@ -264,11 +924,85 @@ type Tooltip struct {
 func GetDefaultToolTip() Tooltip {
 	return Tooltip{
 		Shared:    true,
-		Sort:      0,
+		Sort:      TooltipSortNone,
 		ValueType: "individual",
 	}
 }
 
+// How the series of a shared tooltip are ordered
+const (
+	TooltipSortNone       = 0
+	TooltipSortAscending  = 1
+	TooltipSortDescending = 2
+)
+
+// SetPanelTooltipSort orders the tooltip series by value, e.g. TooltipSortDescending to find the top talker
+// of a stacked graph. The panel is returned unchanged if order isn't valid.
+func SetPanelTooltipSort(p Panel, order int) Panel {
+	if order >= TooltipSortNone && order <= TooltipSortDescending {
+		p.Tooltip.Sort = order
+	}
+	return p
+}
+
+// NullPointMode is how a graph panel draws missing points
+type NullPointMode string
+
+const (
+	NullPointModeNull       NullPointMode = "null"
+	NullPointModeConnected  NullPointMode = "connected"
+	NullPointModeNullAsZero NullPointMode = "null as zero"
+)
+
+// Valid reports whether m is a mode Grafana knows, it silently ignores the others.
+func (m NullPointMode) Valid() bool {
+	return m == NullPointModeNull || m == NullPointModeConnected || m == NullPointModeNullAsZero
+}
+
+// SetPanelNullPointMode sets how missing points are drawn.
+// The panel is returned unchanged with an error if mode isn't valid.
+func SetPanelNullPointMode(p Panel, mode NullPointMode) (Panel, error) {
+	if !mode.Valid() {
+		return p, GrafanaError{0, fmt.Sprintf("invalid nullPointMode %q", mode)}
+	}
+	p.NullPointMode = string(mode)
+	return p, nil
+}
+
+// XaxisMode is what the x axis of a graph panel shows
+type XaxisMode string
+
+const (
+	XaxisModeTime      XaxisMode = "time"
+	XaxisModeSeries    XaxisMode = "series"
+	XaxisModeHistogram XaxisMode = "histogram"
+)
+
+// Valid reports whether m is a mode Grafana knows.
+func (m XaxisMode) Valid() bool {
+	return m == XaxisModeTime || m == XaxisModeSeries || m == XaxisModeHistogram
+}
+
+// SetPanelXAxisMode sets what the x axis shows. Series mode needs the value each series is reduced to,
+// e.g. []string{"total"}, and defaults to it; values are ignored for the time and histogram modes.
+// The panel is returned unchanged with an error if mode isn't valid.
+func SetPanelXAxisMode(p Panel, mode XaxisMode, values []string) (Panel, error) {
+	if !mode.Valid() {
+		return p, GrafanaError{0, fmt.Sprintf("invalid xaxis mode %q", mode)}
+	}
+	p.Xaxis.Mode = string(mode)
+	p.Xaxis.Values = make([]interface{}, 0)
+	if mode == XaxisModeSeries {
+		if len(values) == 0 {
+			values = []string{"total"}
+		}
+		for _, v := range values {
+			p.Xaxis.Values = append(p.Xaxis.Values, v)
+		}
+	}
+	return p, nil
+}
+
 type Xaxis struct {
 	Mode   string        `json:"mode"`
 	Name   interface{}   `json:"name"`
@@ -278,7 +1012,7 @@ type Xaxis struct {
 
 func GetDefaultXaxis() Xaxis {
 	return Xaxis{
-		Mode:   "time",
+		Mode:   string(XaxisModeTime),
 		Name:   nil,
 		Show:   true,
 		Values: make([]interface{}, 0),
@@ -355,33 +1089,209 @@ func GetDefaultTemplate(tagName, measurementName, datasource string) Template {
 	tpl.Multi = true
 	tpl.Name = tagName
 	tpl.Query = "SHOW TAG VALUES FROM \"" + measurementName + "\" WITH  KEY = \"" + tagName + "\""
-	tpl.Refresh = 1
-	tpl.Sort = 0
+	tpl.Refresh = TemplateRefreshOnLoad
+	tpl.Sort = TemplateSortDisabled
 	tpl.Type = "query"
 	tpl.UseTags = false
 	return tpl
 }
 
+// When a template variable queries its values again
+const (
+	TemplateRefreshNever             = 0
+	TemplateRefreshOnLoad            = 1
+	TemplateRefreshOnTimeRangeChange = 2
+)
+
+// SetTemplateRefresh sets when the variable values are queried again, e.g. TemplateRefreshOnTimeRangeChange
+// for a host list that changes over time. The template is returned unchanged if mode isn't valid.
+func SetTemplateRefresh(t Template, mode int) Template {
+	if mode >= TemplateRefreshNever && mode <= TemplateRefreshOnTimeRangeChange {
+		t.Refresh = mode
+	}
+	return t
+}
+
+// How the values of a template variable are sorted in its dropdown
+const (
+	TemplateSortDisabled         = 0
+	TemplateSortAlphabeticalAsc  = 1
+	TemplateSortAlphabeticalDesc = 2
+	TemplateSortNumericalAsc     = 3
+	TemplateSortNumericalDesc    = 4
+)
+
+// SetTemplateSort sets how the variable values are sorted, they keep the query order by default.
+// The template is returned unchanged if mode isn't valid.
+func SetTemplateSort(t Template, mode int) Template {
+	if mode >= TemplateSortDisabled && mode <= TemplateSortNumericalDesc {
+		t.Sort = mode
+	}
+	return t
+}
+
+// compileTemplateRegex compiles a template regex written as Grafana expects it, either /pattern/flags or a bare pattern.
+func compileTemplateRegex(regex string) (*regexp.Regexp, error) {
+	pattern := regex
+	if end := strings.LastIndex(regex, "/"); strings.HasPrefix(regex, "/") && end > 0 {
+		pattern = regex[1:end]
+		flags := ""
+		for _, f := range regex[end+1:] {
+			if strings.ContainsRune("ims", f) {
+				flags += string(f)
+			}
+		}
+		if flags != "" {
+			pattern = "(?" + flags + ")" + pattern
+		}
+	}
+	return regexp.Compile(pattern)
+}
+
+// SetTemplateRegex filters the variable values with regex, e.g. /^([^.]+)\./ to keep the short hostname of a FQDN.
+// Grafana displays the first capture group when there is one, and the whole value otherwise.
+// The template is returned unchanged if regex doesn't compile.
+func SetTemplateRegex(t Template, regex string) Template {
+	if _, err := compileTemplateRegex(regex); err == nil {
+		t.Regex = regex
+	}
+	return t
+}
+
 type Session struct {
-	client   *http.Client
-	User     string
-	Password string
-	url      string
+	client       *http.Client
+	transport    *http.Transport
+	User         string
+	Password     string
+	url          string
+	headers      http.Header
+	lastResponse Response
+	mu           sync.Mutex // guards lastResponse, written by every request
+	readOnly     bool
+	debugLog     *log.Logger
+	// dataSourceUIDs caches the datasource name to uid mapping, nil until loaded.
+	// The map is replaced, never modified, under dataSourceMu.
+	dataSourceUIDs map[string]string
+	dataSourceMu   sync.Mutex
+	// StrictValidation makes UpdateDashboard run ValidateDashboard and refuse to upload a dashboard with problems.
+	StrictValidation bool
+}
+
+// A Response contains the metadata of the last http response received by a Session
+type Response struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+}
+
+// LastResponse returns the status and headers of the last http response, useful to debug proxies and caches.
+func (s *Session) LastResponse() Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastResponse
+}
+
+// A SessionOption configures a Session when it is created by NewSession
+type SessionOption func(*Session)
+
+// TransportOptions tunes the connection pool of a Session
+type TransportOptions struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+}
+
+func GetDefaultTransportOptions() TransportOptions {
+	return TransportOptions{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
+// WithTransportOptions sets the connection pool settings, e.g. to reuse more connections during bulk operations.
+func WithTransportOptions(opts TransportOptions) SessionOption {
+	return func(s *Session) {
+		s.transport.MaxIdleConns = opts.MaxIdleConns
+		s.transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+		s.transport.IdleConnTimeout = opts.IdleConnTimeout
+	}
+}
+
+// ErrReadOnly is returned by the requests that would modify Grafana on a ReadOnly session
+var ErrReadOnly = GrafanaError{0, "session is read-only"}
+
+// ReadOnly makes the session refuse every request that could modify Grafana, returning ErrReadOnly
+// without sending it. Only GET requests and Login are allowed. Methods that look something up
+// before modifying it fail before the lookup.
+func ReadOnly() SessionOption {
+	return func(s *Session) {
+		s.readOnly = true
+	}
 }
 
-func NewSession(user string, password string, url string) *Session {
+// WithDebugLog logs the method, url, status and duration of every request, and which API variant
+// methods choose when it depends on the server, e.g. uid or slug lookups.
+func WithDebugLog(logger *log.Logger) SessionOption {
+	return func(s *Session) {
+		s.debugLog = logger
+	}
+}
+
+// debugf logs to the debug logger of the session, if any.
+func (s *Session) debugf(format string, args ...interface{}) {
+	if s.debugLog != nil {
+		s.debugLog.Printf(format, args...)
+	}
+}
+
+func NewSession(user string, password string, url string, opts ...SessionOption) *Session {
 	jar, err := cookiejar.New(nil)
 	if err != nil {
 		log.Fatal(err)
 	}
-	client := http.Client{Jar: jar, Timeout: time.Second * timeout}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
 	if protocolRegexp.MatchString(url) {
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-		client.Transport = tr
+		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	client := http.Client{Jar: jar, Timeout: time.Second * timeout, Transport: tr}
+	s := &Session{client: &client, transport: tr, User: user, Password: password, url: url, headers: http.Header{}}
+	WithTransportOptions(GetDefaultTransportOptions())(s)
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithTimeout returns a shallow copy of the session whose requests time out after timeout instead of
+// the session one, e.g. s.WithTimeout(time.Second).Health() for a readiness probe.
+// The copy shares the cookies, headers and connections of s, not its LastResponse.
+func (s *Session) WithTimeout(timeout time.Duration) *Session {
+	client := *s.client
+	client.Timeout = timeout
+	return &Session{
+		client:           &client,
+		transport:        s.transport,
+		User:             s.User,
+		Password:         s.Password,
+		url:              s.url,
+		headers:          s.headers,
+		readOnly:         s.readOnly,
+		debugLog:         s.debugLog,
+		StrictValidation: s.StrictValidation,
+	}
+}
+
+// SetHeader adds a header sent with every request, e.g. for an auth proxy in front of Grafana.
+func (s *Session) SetHeader(key, value string) {
+	s.headers.Set(key, value)
+}
+
+// SetHeaders adds several headers sent with every request, keeping the ones already set.
+func (s *Session) SetHeaders(headers map[string]string) {
+	for key, value := range headers {
+		s.SetHeader(key, value)
 	}
-	return &Session{client: &client, User: user, Password: password, url: url}
 }
 
 func (s *Session) Login() (err error) {
@@ -393,20 +1303,45 @@ func (s *Session) Login() (err error) {
 	return
 
 }
+
+// ValidateCredentials tries to login with a throwaway session and reports whether the credentials are accepted.
+// Rejected credentials return false with no error, an error means the check itself failed.
+func ValidateCredentials(url, user, password string) (bool, error) {
+	err := NewSession(user, password, url).Login()
+	if err == nil {
+		return true, nil
+	}
+	if gErr, ok := err.(GrafanaError); ok && gErr.Code == http.StatusUnauthorized {
+		return false, nil
+	}
+	return false, err
+}
 func (s *Session) httpRequest(method string, url string, body io.Reader) (result io.Reader, err error) {
+	if s.readOnly && method != "GET" && method != "HEAD" && url != s.url+"/login" {
+		return result, ErrReadOnly
+	}
 	request, err := http.NewRequest(method, url, body)
 	request.Header.Set("Content-Type", "application/json")
+	for key, values := range s.headers {
+		request.Header[key] = values
+	}
+	start := time.Now()
 	response, err := s.client.Do(request)
 	if err != nil {
+		s.debugf("%s %s: %s", method, url, err)
 		return result, GrafanaError{0, "Unable to perform the http request"}
 	}
+	s.debugf("%s %s: %s in %s", method, url, response.Status, time.Since(start))
+	s.mu.Lock()
+	s.lastResponse = Response{StatusCode: response.StatusCode, Status: response.Status, Header: response.Header}
+	s.mu.Unlock()
 	//    defer response.Body.Close()
-	if response.StatusCode != 200 {
-		dec := json.NewDecoder(response.Body)
-		var gMess GrafanaMessage
-		dec.Decode(&gMess)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		data, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		gMess := parseGrafanaMessage(data)
 
-		return result, GrafanaError{response.StatusCode, gMess.Message}
+		return result, GrafanaError{response.StatusCode, gMess.Description()}
 	}
 	result = response.Body
 	return
@@ -415,10 +1350,24 @@ func (s *Session) httpRequest(method string, url string, body io.Reader) (result
 func (s *Session) Logout() {
 
 }
+
+// ResetAuth drops the session cookies so that the next Login starts clean,
+// e.g. after Grafana rotated its secret key. Headers and other settings are kept.
+func (s *Session) ResetAuth() (err error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return
+	}
+	s.client.Jar = jar
+	return
+}
 func (s *Session) CreateDashboard(dashboardName string) Dashboard {
 	db := GetDefaultDashBoard(dashboardName)
 	return *db
 }
+func (s *Session) CreateDashboardWithOptions(dashboardName string, opts DashboardOptions) Dashboard {
+	return ApplyDashboardOptions(s.CreateDashboard(dashboardName), opts)
+}
 func (s *Session) AddRowPanel(db Dashboard, panelTitle, influxql string) Dashboard {
 	db.Rows = append(db.Rows, GetDefaultRow(panelTitle, influxql))
 	return db
@@ -429,22 +1378,298 @@ func (s *Session) AddTemplating(db Dashboard, tagNames []string, measurementName
 	return db
 }
 
+// DashboardStats contains the number of elements of a dashboard
+type DashboardStats struct {
+	RowCount         int
+	PanelCount       int
+	TemplateVarCount int
+	TargetCount      int
+}
+
+// allPanels returns every panel of the dashboard: the panels of legacy rows, the grid panels,
+// row panels included, and the panels nested in collapsed row panels.
+func allPanels(db Dashboard) []Panel {
+	panels := make([]Panel, 0)
+	for _, row := range db.Rows {
+		panels = append(panels, row.Panels...)
+	}
+	for _, panel := range db.Panels {
+		panels = append(panels, panel)
+		panels = append(panels, panel.Panels...)
+	}
+	return panels
+}
+
+// maxPanelID returns the highest panel id of the dashboard, 0 if it has no panel.
+func maxPanelID(db Dashboard) int {
+	maxID := 0
+	for _, panel := range allPanels(db) {
+		if panel.ID > maxID {
+			maxID = panel.ID
+		}
+	}
+	return maxID
+}
+
+// mapPanels replaces every panel of the dashboard but row panels with fn(panel), wherever it is laid out.
+// The slices are copied so the caller's dashboard isn't modified.
+func mapPanels(db Dashboard, fn func(Panel) Panel) Dashboard {
+	var mapList func(list []Panel) []Panel
+	mapList = func(list []Panel) []Panel {
+		res := make([]Panel, len(list))
+		for i, panel := range list {
+			if panel.Type == "row" {
+				if panel.Panels != nil {
+					panel.Panels = mapList(panel.Panels)
+				}
+			} else {
+				panel = fn(panel)
+			}
+			res[i] = panel
+		}
+		return res
+	}
+	rows := make([]Row, len(db.Rows))
+	for i, row := range db.Rows {
+		row.Panels = mapList(row.Panels)
+		rows[i] = row
+	}
+	db.Rows = rows
+	if db.Panels != nil {
+		db.Panels = mapList(db.Panels)
+	}
+	return db
+}
+
+func GetDashboardStats(db Dashboard) DashboardStats {
+	stats := DashboardStats{}
+	stats.RowCount = len(db.Rows)
+	stats.TemplateVarCount = len(db.Templating.List)
+	for _, panel := range allPanels(db) {
+		if panel.Type == "row" {
+			stats.RowCount++
+			continue
+		}
+		stats.PanelCount++
+		stats.TargetCount += len(panel.Targets)
+	}
+	return stats
+}
+
+var variableRegexp = regexp.MustCompile(`\$\{(\w+)\}|\$(\w+)`)
+
+// substitute replaces the $var and ${var} references found in vars, leaving the others untouched.
+func substitute(s string, vars map[string]string) string {
+	return variableRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+		m := variableRegexp.FindStringSubmatch(ref)
+		name := m[1] + m[2]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// SubstituteVariables replaces template variable references in panel titles, descriptions and queries
+// with constant values, e.g. for snapshots where the variables can't be resolved anymore.
+func SubstituteVariables(db Dashboard, vars map[string]string) Dashboard {
+	return mapPanels(db, func(panel Panel) Panel {
+		panel.Title = substitute(panel.Title, vars)
+		panel.Description = substitute(panel.Description, vars)
+		targets := make([]Target, len(panel.Targets))
+		for k, target := range panel.Targets {
+			target.Query = substitute(target.Query, vars)
+			targets[k] = target
+		}
+		panel.Targets = targets
+		return panel
+	})
+}
+
+// SetDefaultDatasource sets the datasource of every panel and target of the dashboard,
+// e.g. "${datasource}" together with a datasource template variable.
+func SetDefaultDatasource(db Dashboard, datasource string) Dashboard {
+	return mapPanels(db, func(panel Panel) Panel {
+		panel.Datasource = datasource
+		targets := make([]Target, len(panel.Targets))
+		for k, target := range panel.Targets {
+			target.Datasource = datasource
+			targets[k] = target
+		}
+		panel.Targets = targets
+		return panel
+	})
+}
+
+// SetMinInterval sets the minimum group by interval of every target of the dashboard,
+// e.g. "30s" when all series are written at that resolution.
+func SetMinInterval(db Dashboard, interval string) Dashboard {
+	return mapPanels(db, func(panel Panel) Panel {
+		targets := make([]Target, len(panel.Targets))
+		for k, target := range panel.Targets {
+			targets[k] = SetTargetInterval(target, interval)
+		}
+		panel.Targets = targets
+		return panel
+	})
+}
+
+// MergeDashboards appends the rows and grid panels of overlay to base, the grid panels below those of base.
+// Overlay panels are renumbered after the highest panel id of base to avoid collisions;
+// templating, time and other settings of base are kept.
+func MergeDashboards(base, overlay Dashboard) Dashboard {
+	maxID := maxPanelID(base)
+	renumber := func(list []Panel) []Panel {
+		panels := make([]Panel, len(list))
+		copy(panels, list)
+		for i := range panels {
+			maxID++
+			panels[i].ID = maxID
+		}
+		return panels
+	}
+	rows := make([]Row, 0, len(base.Rows)+len(overlay.Rows))
+	rows = append(rows, base.Rows...)
+	for _, row := range overlay.Rows {
+		row.Panels = renumber(row.Panels)
+		rows = append(rows, row)
+	}
+	base.Rows = rows
+	if len(overlay.Panels) > 0 {
+		bottom := gridBottom(base.Panels)
+		panels := make([]Panel, 0, len(base.Panels)+len(overlay.Panels))
+		panels = append(panels, base.Panels...)
+		for _, panel := range renumber(overlay.Panels) {
+			if panel.Panels != nil {
+				panel.Panels = renumber(panel.Panels)
+			}
+			panel.GridPos = shiftGridPos(panel.GridPos, bottom)
+			panels = append(panels, panel)
+		}
+		base.Panels = panels
+	}
+	return base
+}
+
+// AddDashboardDatasourcePanel adds a row with a panel reusing the query results of another panel through the
+// "-- Dashboard --" datasource, e.g. a table summarizing a graph without querying the database again.
+// On grid dashboards the panel is laid out on the grid. The dashboard is returned unchanged if it has no panel with id sourcePanelID.
+func AddDashboardDatasourcePanel(db Dashboard, title string, sourcePanelID int) Dashboard {
+	found := false
+	for _, panel := range allPanels(db) {
+		found = found || (sourcePanelID != 0 && panel.ID == sourcePanelID && panel.Type != "row")
+	}
+	if !found {
+		return db
+	}
+	row := GetDefaultRow(title, "")
+	panel := row.Panels[0]
+	panel.Datasource = "-- Dashboard --"
+	panel.Targets = []Target{{RefID: "A", PanelID: sourcePanelID}}
+	row.Panels[0] = panel
+	return appendRow(db, row)
+}
+
+// BuildHostDashboard assembles CPU load, memory, disk and network panels filtered by a $host variable.
+// measurement is the CPU load measurement the host values are read from, host is the tag key.
+func BuildHostDashboard(title, measurement, datasource string, host string) Dashboard {
+	db := *GetDefaultDashBoard(title)
+	filter := fmt.Sprintf(`"%s" =~ /^$%s$/ AND $timeFilter`, host, host)
+	queries := []struct {
+		title    string
+		influxql string
+	}{
+		{"CPU Load", fmt.Sprintf(`SELECT mean("last15min") FROM "%s" WHERE %s GROUP BY time(1m) fill(null)`, measurement, filter)},
+		{"Memory", fmt.Sprintf(`SELECT mean("used_percent") FROM "mem" WHERE %s GROUP BY time(1m) fill(null)`, filter)},
+		{"Disk", fmt.Sprintf(`SELECT mean("used_percent") FROM "disk" WHERE %s GROUP BY time(1m), "path" fill(null)`, filter)},
+		{"Network", fmt.Sprintf(`SELECT derivative(mean("bytes_recv"), 1s) FROM "net" WHERE %s GROUP BY time(1m), "interface" fill(null)`, filter)},
+	}
+	for i, q := range queries {
+		row := GetDefaultRow(q.title, q.influxql)
+		row.Panels[0].ID = i + 1
+		row.Panels[0].Datasource = datasource
+		db.Rows = append(db.Rows, row)
+	}
+	db.Templating = GetDefaultTemplating([]string{host}, measurement, datasource)
+	return db
+}
+
 type DashboardUploader struct {
 	Dashboard Dashboard `json:"dashboard"`
 	Overwrite bool      `json:"overwrite"`
 }
 
+// A DashboardSaveResult contains the response of Grafana after a dashboard was saved
+type DashboardSaveResult struct {
+	ID      int    `json:"id"`
+	UID     string `json:"uid"`
+	URL     string `json:"url"`
+	Slug    string `json:"slug"`
+	Status  string `json:"status"`
+	Version int    `json:"version"`
+}
+
 func (s *Session) UpdateDashboard(db Dashboard, overwrite bool) (err error) {
+	_, err = s.SaveDashboard(db, overwrite)
+	return
+}
+
+// SaveDashboard uploads the dashboard and returns the id, uid and version Grafana assigned.
+func (s *Session) SaveDashboard(db Dashboard, overwrite bool) (result DashboardSaveResult, err error) {
+	if s.StrictValidation {
+		errs := ValidateDashboard(db)
+		errs = append(errs, s.ValidateDashboardDataSources(db)...)
+		if len(errs) > 0 {
+			return result, joinErrors(errs)
+		}
+	}
 	reqURL := s.url + "/api/dashboards/db"
 	var content DashboardUploader
 	content.Dashboard = db
 	content.Overwrite = overwrite
 	jsonStr, _ := json.Marshal(content)
-	_, err = s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	body, err := s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	// some proxies strip the body of a successful save, that's not a failure
+	if err = dec.Decode(&result); err == io.EOF {
+		err = nil
+	}
 	return
 }
+
+// GetDashboard returns the dashboard with the given slug, passed as is, see GetDashboardBySlug.
+// Use GetDashboardByTitle to look a dashboard up by its title.
 func (s *Session) GetDashboard(name string) (dashboard DashboardResult, err error) {
-	reqURL := s.url + "/api/dashboards/db/" + name
+	return s.GetDashboardBySlug(name)
+}
+
+// slugRegexp matches the runs of characters Slugify replaces with a dash.
+var slugRegexp = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// Slugify approximates the slug Grafana derives from a dashboard title, e.g. "My Host (prod)" gives
+// "my-host-prod" and "my_dash" is kept. Non ASCII characters are not transliterated as Grafana does,
+// so a title like a Chinese one may give an empty slug: prefer GetDashboardByTitle or uids to slugs.
+func Slugify(title string) string {
+	return strings.Trim(slugRegexp.ReplaceAllString(strings.ToLower(title), "-"), "-")
+}
+
+// GetDashboardBySlug returns the dashboard with the given slug, for servers without uid addressing.
+// slug must be exactly the one Grafana stored, see Slugify.
+func (s *Session) GetDashboardBySlug(slug string) (dashboard DashboardResult, err error) {
+	reqURL := s.url + "/api/dashboards/db/" + slug
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&dashboard)
+	return
+}
+func (s *Session) GetDashboardByUID(uid string) (dashboard DashboardResult, err error) {
+	reqURL := s.url + "/api/dashboards/uid/" + uid
 	body, err := s.httpRequest("GET", reqURL, nil)
 	if err != nil {
 		return
@@ -453,7 +1678,25 @@ func (s *Session) GetDashboard(name string) (dashboard DashboardResult, err erro
 	err = dec.Decode(&dashboard)
 	return
 }
+
+// RenameDashboard changes only the title of a dashboard, keeping its uid, version, folder
+// and the fields Dashboard doesn't model, so no duplicate is created and nothing else is lost.
+func (s *Session) RenameDashboard(uid, newTitle string) (result DashboardSaveResult, err error) {
+	if s.readOnly {
+		return result, ErrReadOnly
+	}
+	dashboard, meta, err := s.getRawDashboardMeta(uid)
+	if err != nil {
+		return
+	}
+	dashboard["title"] = newTitle
+	model, _ := json.Marshal(dashboard)
+	return s.saveRawDashboard(model, meta.FolderID, true)
+}
 func (s *Session) DeleteDashBoard(dashBoardName string) (err error) {
+	if s.readOnly {
+		return ErrReadOnly
+	}
 	dashRes, err := s.GetDashboard(dashBoardName)
 	if err != nil {
 		return
@@ -465,8 +1708,42 @@ func (s *Session) DeleteDashBoard(dashBoardName string) (err error) {
 
 	return nil
 }
-func (s *Session) CreateDataSource() {
 
+// DeleteDashboardByUID deletes the dashboard with the given uid.
+func (s *Session) DeleteDashboardByUID(uid string) (err error) {
+	reqURL := s.url + "/api/dashboards/uid/" + uid
+	_, err = s.httpRequest("DELETE", reqURL, nil)
+	return
+}
+
+// ErrDataSourceExists is returned by CreateDataSource when a datasource with the same name exists
+var ErrDataSourceExists = GrafanaError{http.StatusConflict, "datasource with the same name already exists"}
+
+// CreateDataSource creates the datasource and returns its id, an empty access defaulting to proxy.
+// If the name is taken it returns ErrDataSourceExists, so callers may update the existing one instead.
+func (s *Session) CreateDataSource(ds DataSource) (id int, err error) {
+	if !validAccess(ds.Access) {
+		return 0, GrafanaError{0, fmt.Sprintf("invalid datasource access %q", ds.Access)}
+	}
+	if ds.Access == "" {
+		ds.Access = DataSourceAccessProxy
+	}
+	reqURL := s.url + "/api/datasources"
+	jsonStr, _ := json.Marshal(ds)
+	body, err := s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	if gErr, ok := err.(GrafanaError); ok && gErr.Code == http.StatusConflict {
+		return 0, ErrDataSourceExists
+	}
+	if err != nil {
+		return
+	}
+	s.RefreshDataSourceCache()
+	var res struct {
+		ID int `json:"id"`
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&res)
+	return res.ID, err
 }
 func (s *Session) DeleteDataSource() {
 
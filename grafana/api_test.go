@@ -0,0 +1,22 @@
+package grafana
+
+import "testing"
+
+func TestParseGrafanaMessage(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"message": "Dashboard not found"}`, "Dashboard not found"},
+		{`{"message": "Not found", "status": 404}`, "Not found; status: 404"},
+		{`{"message": "Invalid", "data": "oops", "status": "error"}`, "Invalid; status: error"},
+		{`{"message": "Bad", "data": {"validationMessage": "url is required"}}`, "Bad; validation: url is required"},
+		{`[{"fieldNames": ["Name"], "classification": "RequiredError", "message": "Required"}]`, "Name RequiredError: Required"},
+		{"bad gateway\n", "bad gateway"},
+	}
+	for _, tt := range tests {
+		if got := parseGrafanaMessage([]byte(tt.body)).Description(); got != tt.want {
+			t.Errorf("parseGrafanaMessage(%s) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
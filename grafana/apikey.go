@@ -0,0 +1,35 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// An APIKey contains the description of an API key, the key itself is never returned after creation.
+// Expiration is zero for keys that don't expire.
+type APIKey struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	Role       string    `json:"role"`
+	Expiration time.Time `json:"expiration"`
+}
+
+// ListAPIKeys returns the API keys of the current organization, expired ones included.
+func (s *Session) ListAPIKeys() (keys []APIKey, err error) {
+	reqURL := s.url + "/api/auth/keys?includeExpired=true"
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&keys)
+	return
+}
+
+// DeleteAPIKey revokes the API key with the given id.
+func (s *Session) DeleteAPIKey(id int) (err error) {
+	reqURL := fmt.Sprintf("%s/api/auth/keys/%d", s.url, id)
+	_, err = s.httpRequest("DELETE", reqURL, nil)
+	return
+}
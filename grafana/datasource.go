@@ -0,0 +1,366 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// A DataSource contains the configuration of a Grafana datasource.
+// Plain settings go to JSONData, secrets must go to SecureJSONData which Grafana encrypts and never returns.
+type DataSource struct {
+	ID             int                    `json:"id,omitempty"`
+	UID            string                 `json:"uid,omitempty"`
+	OrgID          int                    `json:"orgId,omitempty"`
+	Name           string                 `json:"name"`
+	Type           string                 `json:"type"`
+	Access         string                 `json:"access"`
+	URL            string                 `json:"url"`
+	Database       string                 `json:"database,omitempty"`
+	User           string                 `json:"user,omitempty"`
+	BasicAuth      bool                   `json:"basicAuth"`
+	BasicAuthUser  string                 `json:"basicAuthUser,omitempty"`
+	IsDefault      bool                   `json:"isDefault"`
+	JSONData       map[string]interface{} `json:"jsonData,omitempty"`
+	SecureJSONData map[string]string      `json:"secureJsonData,omitempty"`
+}
+
+// Datasource access modes. Direct makes browsers query the datasource and exposes its url, prefer proxy.
+const (
+	DataSourceAccessProxy  = "proxy"
+	DataSourceAccessDirect = "direct"
+)
+
+// NewDataSource returns a datasource going through the Grafana proxy.
+func NewDataSource(name, dsType, url string) DataSource {
+	return DataSource{Name: name, Type: dsType, Access: DataSourceAccessProxy, URL: url}
+}
+
+// validAccess reports whether access is a known mode, an empty one being the proxy default.
+func validAccess(access string) bool {
+	return access == "" || access == DataSourceAccessProxy || access == DataSourceAccessDirect
+}
+
+// SetDataSourceCredentials sets the user and password the datasource logs in with, e.g. InfluxDB auth.
+// The password is stored in secureJsonData.password, never in plain jsonData.
+func SetDataSourceCredentials(ds DataSource, user, password string) DataSource {
+	ds.User = user
+	ds.SecureJSONData = setSecret(ds.SecureJSONData, "password", password)
+	return ds
+}
+
+// SetDataSourceBasicAuth enables http basic auth in front of the datasource.
+// The password is stored in secureJsonData.basicAuthPassword.
+func SetDataSourceBasicAuth(ds DataSource, user, password string) DataSource {
+	ds.BasicAuth = true
+	ds.BasicAuthUser = user
+	ds.SecureJSONData = setSecret(ds.SecureJSONData, "basicAuthPassword", password)
+	return ds
+}
+
+// setSecret copies secrets before writing so that DataSource values don't share the map.
+func setSecret(secrets map[string]string, key, value string) map[string]string {
+	res := make(map[string]string, len(secrets)+1)
+	for k, v := range secrets {
+		res[k] = v
+	}
+	res[key] = value
+	return res
+}
+
+// setJSONData copies jsonData before writing so that DataSource values don't share the map.
+func setJSONData(jsonData map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(jsonData)+1)
+	for k, v := range jsonData {
+		res[k] = v
+	}
+	res[key] = value
+	return res
+}
+
+// SetDataSourceTimeout sets how many seconds Grafana waits for the datasource to answer a query.
+// The datasource is returned unchanged if seconds isn't positive.
+func SetDataSourceTimeout(ds DataSource, seconds int) DataSource {
+	if seconds > 0 {
+		ds.JSONData = setJSONData(ds.JSONData, "timeout", seconds)
+	}
+	return ds
+}
+
+// SetDataSourceHTTPMethod sets the method queries are sent with, POST allowing longer InfluxDB queries.
+// The datasource is returned unchanged if method isn't "GET" or "POST".
+func SetDataSourceHTTPMethod(ds DataSource, method string) DataSource {
+	if method == "GET" || method == "POST" {
+		ds.JSONData = setJSONData(ds.JSONData, "httpMethod", method)
+	}
+	return ds
+}
+
+// SetDataSourceTLSSkipVerify disables the verification of the datasource certificate, e.g. a self-signed one.
+func SetDataSourceTLSSkipVerify(ds DataSource, skip bool) DataSource {
+	ds.JSONData = setJSONData(ds.JSONData, "tlsSkipVerify", skip)
+	return ds
+}
+
+// NewInfluxDBV2DataSource returns an InfluxDB 2.x datasource using Flux and token auth.
+// The token is sent as secureJsonData.token.
+func NewInfluxDBV2DataSource(name, url, organization, defaultBucket, token string) DataSource {
+	ds := NewDataSource(name, "influxdb", url)
+	ds.JSONData = map[string]interface{}{
+		"version":       "Flux",
+		"organization":  organization,
+		"defaultBucket": defaultBucket,
+	}
+	ds.SecureJSONData = setSecret(nil, "token", token)
+	return ds
+}
+
+// ListDataSources returns all datasources configured in the current organization.
+// A response that isn't a json array, e.g. an HTML page from a proxy, is reported as a GrafanaError.
+func (s *Session) ListDataSources() (dataSources []DataSource, err error) {
+	reqURL := s.url + "/api/datasources"
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	var raw json.RawMessage
+	dec := json.NewDecoder(body)
+	if err = dec.Decode(&raw); err != nil || !bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		return nil, GrafanaError{0, fmt.Sprintf("%s did not return a json array of datasources", reqURL)}
+	}
+	err = json.Unmarshal(raw, &dataSources)
+	return
+}
+
+// GetDataSource returns the datasource with the given id.
+func (s *Session) GetDataSource(id int) (ds DataSource, err error) {
+	reqURL := fmt.Sprintf("%s/api/datasources/%d", s.url, id)
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&ds)
+	return
+}
+
+// A DataSourceCache contains the query caching configuration of a datasource (Grafana Enterprise)
+type DataSourceCache struct {
+	DataSourceID   int   `json:"dataSourceID"`
+	Enabled        bool  `json:"enabled"`
+	UseDefaultTTL  bool  `json:"useDefaultTTL"`
+	TTLQueriesMs   int64 `json:"ttlQueriesMs"`
+	TTLResourcesMs int64 `json:"ttlResourcesMs"`
+}
+
+// GetDataSourceCache returns the query caching configuration of the datasource.
+func (s *Session) GetDataSourceCache(id int) (cache DataSourceCache, err error) {
+	reqURL := fmt.Sprintf("%s/api/datasources/%d/cache", s.url, id)
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&cache)
+	return
+}
+
+// SetDataSourceCache enables or disables query caching for the datasource.
+// A zero ttl keeps the server default TTL.
+func (s *Session) SetDataSourceCache(id int, enabled bool, ttl time.Duration) (err error) {
+	cache := DataSourceCache{DataSourceID: id, Enabled: enabled, UseDefaultTTL: ttl == 0}
+	if ttl != 0 {
+		cache.TTLQueriesMs = int64(ttl / time.Millisecond)
+		cache.TTLResourcesMs = cache.TTLQueriesMs
+	}
+	reqURL := fmt.Sprintf("%s/api/datasources/%d/cache", s.url, id)
+	jsonStr, _ := json.Marshal(cache)
+	_, err = s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	return
+}
+
+// CopyDataSource creates a datasource with the settings of an existing one under a new name and url.
+// Secrets can't be read back from Grafana so they are not copied and must be set again.
+// The uid isn't copied either, Grafana generates a new one.
+func (s *Session) CopyDataSource(srcID int, newName, newURL string) (ds DataSource, err error) {
+	if s.readOnly {
+		return ds, ErrReadOnly
+	}
+	ds, err = s.GetDataSource(srcID)
+	if err != nil {
+		return
+	}
+	ds.ID = 0
+	ds.OrgID = 0
+	ds.UID = ""
+	ds.IsDefault = false
+	ds.SecureJSONData = nil
+	ds.Name = newName
+	ds.URL = newURL
+	ds.ID, err = s.CreateDataSource(ds)
+	return
+}
+
+// CreateDataSources creates every datasource and returns the created ones with their id set.
+// A failure doesn't stop the others, the failures are returned together as one error.
+func (s *Session) CreateDataSources(dss []DataSource) (created []DataSource, err error) {
+	created = make([]DataSource, 0, len(dss))
+	errs := make([]error, 0)
+	for _, ds := range dss {
+		id, err := s.CreateDataSource(ds)
+		if err != nil {
+			errs = append(errs, GrafanaError{0, fmt.Sprintf("datasource %q: %s", ds.Name, err)})
+			continue
+		}
+		ds.ID = id
+		if ds.Access == "" {
+			ds.Access = DataSourceAccessProxy
+		}
+		created = append(created, ds)
+	}
+	if len(errs) > 0 {
+		err = joinErrors(errs)
+	}
+	return
+}
+
+// DataSourceUIDMap returns the uid of every datasource by name, e.g. to reference datasources by uid in panels.
+// The mapping is fetched once per session, see RefreshDataSourceCache.
+func (s *Session) DataSourceUIDMap() (uids map[string]string, err error) {
+	s.dataSourceMu.Lock()
+	cached := s.dataSourceUIDs
+	s.dataSourceMu.Unlock()
+	if cached == nil {
+		dataSources, err := s.ListDataSources()
+		if err != nil {
+			return nil, err
+		}
+		cached = make(map[string]string, len(dataSources))
+		for _, ds := range dataSources {
+			cached[ds.Name] = ds.UID
+		}
+		s.dataSourceMu.Lock()
+		s.dataSourceUIDs = cached
+		s.dataSourceMu.Unlock()
+	}
+	uids = make(map[string]string, len(cached))
+	for name, uid := range cached {
+		uids[name] = uid
+	}
+	return
+}
+
+// RefreshDataSourceCache drops the cached datasource uids, e.g. after datasources were changed outside the session.
+func (s *Session) RefreshDataSourceCache() {
+	s.dataSourceMu.Lock()
+	s.dataSourceUIDs = nil
+	s.dataSourceMu.Unlock()
+}
+
+// PingDataSource checks that Grafana can reach the datasource, not only that it is configured.
+// InfluxQL datasources run SHOW DATABASES through the proxy, other types use the Grafana health check.
+func (s *Session) PingDataSource(id int) (err error) {
+	ds, err := s.GetDataSource(id)
+	if err != nil {
+		return
+	}
+	if ds.Type == "influxdb" && ds.JSONData["version"] != "Flux" {
+		s.debugf("datasource %q: ping with InfluxQL", ds.Name)
+		_, err = s.queryInfluxDB(id, ds.Database, "SHOW DATABASES")
+	} else {
+		s.debugf("datasource %q: ping with the health check", ds.Name)
+		reqURL := s.url + "/api/datasources/uid/" + ds.UID + "/health"
+		_, err = s.httpRequest("GET", reqURL, nil)
+	}
+	if err != nil {
+		return GrafanaError{0, fmt.Sprintf("datasource %q is unreachable: %s", ds.Name, err)}
+	}
+	return
+}
+
+// ReplaceDataSourceInDashboards points every panel, target, template variable and annotation using the
+// datasource oldName to newName, by name or by uid, and uploads the changed dashboards with overwrite.
+// It returns the number of dashboards changed; failing dashboards don't stop the others.
+func (s *Session) ReplaceDataSourceInDashboards(oldName, newName string) (int, error) {
+	if s.readOnly {
+		return 0, ErrReadOnly
+	}
+	uids, err := s.DataSourceUIDMap()
+	if err != nil {
+		return 0, err
+	}
+	newUID, ok := uids[newName]
+	if !ok {
+		return 0, GrafanaError{0, fmt.Sprintf("datasource %q not found", newName)}
+	}
+	oldUID := uids[oldName]
+	query := url.Values{}
+	query.Set("type", "dash-db")
+	results, err := s.search(query)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	errs := make([]error, 0)
+	for _, res := range results {
+		dashboard, err := s.getRawDashboard(res.UID)
+		if err != nil {
+			errs = append(errs, GrafanaError{0, fmt.Sprintf("dashboard %q: %s", res.Title, err)})
+			continue
+		}
+		changed := false
+		replaceDataSources(dashboard, func(ref interface{}) interface{} {
+			switch v := ref.(type) {
+			case string:
+				if v == oldName {
+					changed = true
+					return newName
+				}
+			case map[string]interface{}:
+				if uid, _ := v["uid"].(string); uid != "" && uid == oldUID {
+					changed = true
+					updated := make(map[string]interface{}, len(v))
+					for k, val := range v {
+						updated[k] = val
+					}
+					updated["uid"] = newUID
+					return updated
+				}
+			}
+			return ref
+		})
+		if !changed {
+			continue
+		}
+		model, _ := json.Marshal(dashboard)
+		if _, err := s.saveRawDashboard(model, res.FolderID, true); err != nil {
+			errs = append(errs, GrafanaError{0, fmt.Sprintf("dashboard %q: %s", res.Title, err)})
+			continue
+		}
+		count++
+	}
+	if len(errs) > 0 {
+		return count, joinErrors(errs)
+	}
+	return count, nil
+}
+
+// UpdateDataSource replaces the configuration of the datasource with id ds.ID,
+// e.g. after CreateDataSource returned ErrDataSourceExists.
+func (s *Session) UpdateDataSource(ds DataSource) (err error) {
+	if !validAccess(ds.Access) {
+		return GrafanaError{0, fmt.Sprintf("invalid datasource access %q", ds.Access)}
+	}
+	if ds.Access == "" {
+		ds.Access = DataSourceAccessProxy
+	}
+	reqURL := fmt.Sprintf("%s/api/datasources/%d", s.url, ds.ID)
+	jsonStr, _ := json.Marshal(ds)
+	_, err = s.httpRequest("PUT", reqURL, bytes.NewBuffer(jsonStr))
+	if err == nil {
+		s.RefreshDataSourceCache()
+	}
+	return
+}
@@ -0,0 +1,57 @@
+package grafana
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// marshalDataSource returns the json Grafana receives for ds, decoded as a map.
+func marshalDataSource(t *testing.T, ds DataSource) map[string]interface{} {
+	data, err := json.Marshal(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res map[string]interface{}
+	if err = json.Unmarshal(data, &res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestDataSourcePasswordsAreSecure(t *testing.T) {
+	ds := DataSource{Name: "influx", Type: "influxdb", JSONData: map[string]interface{}{"httpMode": "GET"}}
+	ds = SetDataSourceCredentials(ds, "reader", "secret")
+	ds = SetDataSourceBasicAuth(ds, "proxy", "basic-secret")
+	res := marshalDataSource(t, ds)
+
+	secure, _ := res["secureJsonData"].(map[string]interface{})
+	if secure["password"] != "secret" {
+		t.Errorf("secureJsonData.password = %v, want %q", secure["password"], "secret")
+	}
+	if secure["basicAuthPassword"] != "basic-secret" {
+		t.Errorf("secureJsonData.basicAuthPassword = %v, want %q", secure["basicAuthPassword"], "basic-secret")
+	}
+	if res["user"] != "reader" || res["basicAuthUser"] != "proxy" || res["basicAuth"] != true {
+		t.Errorf("user = %v, basicAuthUser = %v, basicAuth = %v", res["user"], res["basicAuthUser"], res["basicAuth"])
+	}
+	jsonData, _ := res["jsonData"].(map[string]interface{})
+	for _, key := range []string{"password", "basicAuthPassword"} {
+		if _, ok := jsonData[key]; ok {
+			t.Errorf("jsonData has %s", key)
+		}
+		if _, ok := res[key]; ok {
+			t.Errorf("datasource has plain %s", key)
+		}
+	}
+}
+
+func TestSetDataSourceCredentialsCopiesSecrets(t *testing.T) {
+	ds := SetDataSourceCredentials(DataSource{}, "reader", "secret")
+	other := SetDataSourceBasicAuth(ds, "proxy", "basic-secret")
+	if _, ok := ds.SecureJSONData["basicAuthPassword"]; ok {
+		t.Error("SetDataSourceBasicAuth modified the secrets of its input")
+	}
+	if other.SecureJSONData["password"] != "secret" {
+		t.Errorf("password = %q, want %q", other.SecureJSONData["password"], "secret")
+	}
+}
@@ -0,0 +1,116 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// fetchLive returns the live version of db, looked up by uid or else by title.
+// found is false if the dashboard doesn't exist on the server.
+func (s *Session) fetchLive(db Dashboard) (live Dashboard, found bool, err error) {
+	var dashRes DashboardResult
+	if db.UID != "" {
+		s.debugf("dashboard %q: lookup by uid %s", db.Title, db.UID)
+		dashRes, err = s.GetDashboardByUID(db.UID)
+	} else {
+		s.debugf("dashboard %q: lookup by title", db.Title)
+		dashRes, err = s.GetDashboardByTitle(db.Title)
+	}
+	if gErr, ok := err.(GrafanaError); ok && gErr.Code == http.StatusNotFound {
+		return live, false, nil
+	}
+	if err != nil {
+		return
+	}
+	return dashRes.Model, true, nil
+}
+
+// sameJSON reports whether a and b marshal to the same json.
+func sameJSON(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// NeedsUpdate reports whether db differs from the live dashboard in its title, rows and panels,
+// templating or time, so that unchanged dashboards aren't uploaded again and don't bump the version.
+// A dashboard missing on the server needs an update.
+func (s *Session) NeedsUpdate(db Dashboard) (bool, error) {
+	live, found, err := s.fetchLive(db)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return true, nil
+	}
+	same := live.Title == db.Title &&
+		sameJSON(live.Rows, db.Rows) &&
+		sameJSON(live.Panels, db.Panels) &&
+		sameJSON(live.Templating, db.Templating) &&
+		live.Time == db.Time
+	return !same, nil
+}
+
+// A DriftReport lists how a live dashboard differs from its desired definition
+type DriftReport struct {
+	// Missing is true if the dashboard doesn't exist on the server
+	Missing bool
+	// Fields are the json names of the dashboard fields that differ, e.g. "rows" or "time"
+	Fields []string
+	// AddedPanels are live panels whose id isn't in the desired dashboard, added in the UI.
+	// Panels of rows, of the grid and nested in collapsed rows are all considered
+	AddedPanels []Panel
+}
+
+// Drifted reports whether the live dashboard differs from the desired one.
+func (r DriftReport) Drifted() bool {
+	return r.Missing || len(r.Fields) > 0
+}
+
+// DetectDrift compares the live dashboard, looked up by uid or else by title, with desired.
+// Server managed fields like id and version are ignored.
+func (s *Session) DetectDrift(desired Dashboard) (report DriftReport, err error) {
+	live, found, err := s.fetchLive(desired)
+	if err != nil {
+		return
+	}
+	report.Fields = make([]string, 0)
+	report.AddedPanels = make([]Panel, 0)
+	if !found {
+		report.Missing = true
+		return
+	}
+	fields := []struct {
+		name          string
+		live, desired interface{}
+	}{
+		{"title", live.Title, desired.Title},
+		{"tags", live.Tags, desired.Tags},
+		{"editable", live.Editable, desired.Editable},
+		{"graphTooltip", live.GraphTooltip, desired.GraphTooltip},
+		{"hideControls", live.HideControls, desired.HideControls},
+		{"links", live.Links, desired.Links},
+		{"panels", live.Panels, desired.Panels},
+		{"rows", live.Rows, desired.Rows},
+		{"templating", live.Templating, desired.Templating},
+		{"time", live.Time, desired.Time},
+		{"timepicker", live.Timepicker, desired.Timepicker},
+		{"timezone", live.Timezone, desired.Timezone},
+	}
+	for _, f := range fields {
+		if !sameJSON(f.live, f.desired) {
+			report.Fields = append(report.Fields, f.name)
+		}
+	}
+	desiredIDs := make(map[int]bool)
+	for _, panel := range allPanels(desired) {
+		desiredIDs[panel.ID] = true
+	}
+	for _, panel := range allPanels(live) {
+		if panel.Type != "row" && !desiredIDs[panel.ID] {
+			report.AddedPanels = append(report.AddedPanels, panel)
+		}
+	}
+	return
+}
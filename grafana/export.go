@@ -0,0 +1,162 @@
+package grafana
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// A DashboardInput is a placeholder of a shareable dashboard, filled with a concrete datasource on import
+type DashboardInput struct {
+	Name        string `json:"name"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	PluginID    string `json:"pluginId"`
+	PluginName  string `json:"pluginName"`
+}
+
+// getRawDashboard returns the json of the dashboard with the given uid, keeping the fields Dashboard doesn't model.
+func (s *Session) getRawDashboard(uid string) (dashboard map[string]interface{}, err error) {
+	dashboard, _, err = s.getRawDashboardMeta(uid)
+	return
+}
+
+// getRawDashboardMeta returns the json of the dashboard with the given uid and its meta, e.g. its folder.
+func (s *Session) getRawDashboardMeta(uid string) (dashboard map[string]interface{}, meta Meta, err error) {
+	reqURL := s.url + "/api/dashboards/uid/" + uid
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	var res struct {
+		Dashboard map[string]interface{} `json:"dashboard"`
+		Meta      Meta                   `json:"meta"`
+		Model     map[string]interface{} `json:"model"`
+	}
+	dec := json.NewDecoder(body)
+	if err = dec.Decode(&res); err != nil {
+		return
+	}
+	if res.Dashboard != nil {
+		return res.Dashboard, res.Meta, nil
+	}
+	return res.Model, res.Meta, nil
+}
+
+// inputName returns the placeholder name Grafana gives to a datasource, e.g. DS_INFLUX_PROD.
+func inputName(dsName string) string {
+	return "DS_" + strings.ToUpper(strings.Trim(nonAlphaNumRegexp.ReplaceAllString(strings.ToLower(dsName), "_"), "_"))
+}
+
+// shareableExporter replaces datasource references with inputs, as Grafana "Export for sharing externally" does.
+type shareableExporter struct {
+	byName map[string]DataSource
+	byUID  map[string]DataSource
+	inputs []DashboardInput
+	seen   map[string]bool
+}
+
+// input registers the input of the datasource and returns its ${DS_...} reference.
+func (e *shareableExporter) input(ds DataSource) string {
+	name := inputName(ds.Name)
+	if !e.seen[name] {
+		e.seen[name] = true
+		e.inputs = append(e.inputs, DashboardInput{
+			Name:       name,
+			Label:      ds.Name,
+			Type:       "datasource",
+			PluginID:   ds.Type,
+			PluginName: ds.Type,
+		})
+	}
+	return "${" + name + "}"
+}
+
+// datasource returns the reference replacing ref, which is a datasource name or a {"type", "uid"} object.
+// Builtin datasources, template variables and unknown datasources are kept.
+func (e *shareableExporter) datasource(ref interface{}) interface{} {
+	switch v := ref.(type) {
+	case string:
+		if ds, ok := e.byName[v]; ok && !builtinDataSources[v] && !strings.HasPrefix(v, "$") {
+			return e.input(ds)
+		}
+	case map[string]interface{}:
+		uid, _ := v["uid"].(string)
+		if ds, ok := e.byUID[uid]; ok && uid != "" && !strings.HasPrefix(uid, "$") {
+			res := make(map[string]interface{}, len(v))
+			for k, val := range v {
+				res[k] = val
+			}
+			res["uid"] = e.input(ds)
+			return res
+		}
+	}
+	return ref
+}
+
+// objects calls fn on every object of the json list, which may be missing.
+func objects(list interface{}, fn func(map[string]interface{})) {
+	items, _ := list.([]interface{})
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok {
+			fn(obj)
+		}
+	}
+}
+
+// replaceDataSources replaces every datasource reference of a dashboard json with fn(ref):
+// in rows, panels, nested panels of collapsed rows, targets, template variables and annotations.
+func replaceDataSources(dashboard map[string]interface{}, fn func(interface{}) interface{}) {
+	replace := func(obj map[string]interface{}) {
+		if ds, ok := obj["datasource"]; ok {
+			obj["datasource"] = fn(ds)
+		}
+	}
+	var panels func(list interface{})
+	panels = func(list interface{}) {
+		objects(list, func(panel map[string]interface{}) {
+			replace(panel)
+			objects(panel["targets"], replace)
+			panels(panel["panels"])
+		})
+	}
+	objects(dashboard["rows"], func(row map[string]interface{}) {
+		panels(row["panels"])
+	})
+	panels(dashboard["panels"])
+	for _, section := range []string{"templating", "annotations"} {
+		if obj, ok := dashboard[section].(map[string]interface{}); ok {
+			objects(obj["list"], replace)
+		}
+	}
+}
+
+// ExportShareable returns the dashboard as Grafana exports it for sharing externally: every datasource
+// reference is replaced by a ${DS_...} placeholder declared in __inputs, and the id is cleared.
+// Use ImportWithInputs to upload it with concrete datasources.
+func (s *Session) ExportShareable(uid string) (model json.RawMessage, err error) {
+	dashboard, err := s.getRawDashboard(uid)
+	if err != nil {
+		return
+	}
+	dataSources, err := s.ListDataSources()
+	if err != nil {
+		return
+	}
+	e := &shareableExporter{
+		byName: make(map[string]DataSource, len(dataSources)),
+		byUID:  make(map[string]DataSource, len(dataSources)),
+		inputs: make([]DashboardInput, 0),
+		seen:   make(map[string]bool),
+	}
+	for _, ds := range dataSources {
+		e.byName[ds.Name] = ds
+		if ds.UID != "" {
+			e.byUID[ds.UID] = ds
+		}
+	}
+	replaceDataSources(dashboard, e.datasource)
+	dashboard["__inputs"] = e.inputs
+	dashboard["id"] = nil
+	return json.Marshal(dashboard)
+}
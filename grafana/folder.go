@@ -0,0 +1,81 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Permission levels of a dashboard or folder
+const (
+	PermissionView  = 1
+	PermissionEdit  = 2
+	PermissionAdmin = 4
+)
+
+// A DashboardPermission grants a permission level to a user, a team or an organization role.
+// Only one of UserID, TeamID and Role is set.
+type DashboardPermission struct {
+	UserID         int    `json:"userId,omitempty"`
+	UserLogin      string `json:"userLogin,omitempty"`
+	TeamID         int    `json:"teamId,omitempty"`
+	Team           string `json:"team,omitempty"`
+	Role           string `json:"role,omitempty"`
+	Permission     int    `json:"permission"`
+	PermissionName string `json:"permissionName,omitempty"`
+}
+
+// GetFolderPermissions returns the permissions of the folder, inherited by its dashboards.
+func (s *Session) GetFolderPermissions(uid string) (perms []DashboardPermission, err error) {
+	reqURL := s.url + "/api/folders/" + uid + "/permissions"
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&perms)
+	return
+}
+
+// SetFolderPermissions replaces all permissions of the folder with perms.
+func (s *Session) SetFolderPermissions(uid string, perms []DashboardPermission) (err error) {
+	reqURL := s.url + "/api/folders/" + uid + "/permissions"
+	items := make([]DashboardPermission, 0, len(perms))
+	for _, p := range perms {
+		items = append(items, DashboardPermission{UserID: p.UserID, TeamID: p.TeamID, Role: p.Role, Permission: p.Permission})
+	}
+	jsonStr, _ := json.Marshal(map[string][]DashboardPermission{"items": items})
+	_, err = s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	return
+}
+
+// A Folder contains the description of a dashboard folder
+type Folder struct {
+	ID    int    `json:"id"`
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+}
+
+// ListFolders returns the dashboard folders of the current organization.
+func (s *Session) ListFolders() (folders []Folder, err error) {
+	reqURL := s.url + "/api/folders"
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&folders)
+	return
+}
+
+// CreateFolder creates a dashboard folder with the given title.
+func (s *Session) CreateFolder(title string) (folder Folder, err error) {
+	reqURL := s.url + "/api/folders"
+	jsonStr, _ := json.Marshal(map[string]string{"title": title})
+	body, err := s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&folder)
+	return
+}
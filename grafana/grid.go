@@ -0,0 +1,72 @@
+package grafana
+
+// AddGridPanel appends the panel to the grid layout of the dashboard, w columns wide out of 24 and h cells high.
+// It goes right of the last panel if it fits, else on a new line; panels with id 0 are numbered.
+// A dashboard older than schema 16, the first with the grid layout, is raised to it and its rows are
+// converted to grid panels first, as Grafana ignores rows from that version.
+// A panel repeated horizontally with SetPanelRepeat takes a full line, Grafana sharing its width
+// between the copies, maxPerRow per line.
+func AddGridPanel(db Dashboard, p Panel, w, h int) Dashboard {
+	if w <= 0 || w > gridColumns {
+		w = gridColumns
+	}
+	if h <= 0 {
+		h = gridHeight("")
+	}
+	horizontal := func(panel Panel) bool {
+		return panel.Repeat != "" && panel.RepeatDirection != "v"
+	}
+	if horizontal(p) {
+		w = gridColumns
+	}
+	if db.SchemaVersion < 16 {
+		db = rowsToPanels(db)
+		db.SchemaVersion = 16
+	}
+	pos := GridPos{H: h, W: w, X: 0, Y: gridBottom(db.Panels)}
+	if n := len(db.Panels); n > 0 && !horizontal(p) {
+		last := db.Panels[n-1]
+		if last.GridPos != nil && last.Type != "row" && !horizontal(last) && last.GridPos.X+last.GridPos.W+w <= gridColumns {
+			pos.X = last.GridPos.X + last.GridPos.W
+			pos.Y = last.GridPos.Y
+		}
+	}
+	if p.ID == 0 {
+		p.ID = maxPanelID(db) + 1
+	}
+	p.GridPos = &pos
+	panels := make([]Panel, 0, len(db.Panels)+1)
+	panels = append(panels, db.Panels...)
+	db.Panels = append(panels, p)
+	return db
+}
+
+// appendRow adds the row at the bottom of the dashboard, its panels numbered after every other panel.
+// On a grid dashboard, schema 16 or later or with grid panels, Grafana ignores legacy rows, so the row
+// is converted to grid panels starting a new line, the dashboard's own rows being converted first.
+func appendRow(db Dashboard, row Row) Dashboard {
+	grid := db.SchemaVersion >= 16 || len(db.Panels) > 0
+	if grid && db.SchemaVersion < 16 {
+		db = rowsToPanels(db)
+		db.SchemaVersion = 16
+	}
+	maxID := maxPanelID(db)
+	panels := make([]Panel, len(row.Panels))
+	for i, panel := range row.Panels {
+		maxID++
+		panel.ID = maxID
+		panels[i] = panel
+	}
+	row.Panels = panels
+	if grid {
+		converted := rowsToGrid([]Row{row}, maxID, gridBottom(db.Panels))
+		panels = make([]Panel, 0, len(db.Panels)+len(converted))
+		panels = append(panels, db.Panels...)
+		db.Panels = append(panels, converted...)
+		return db
+	}
+	rows := make([]Row, 0, len(db.Rows)+1)
+	rows = append(rows, db.Rows...)
+	db.Rows = append(rows, row)
+	return db
+}
@@ -0,0 +1,84 @@
+package grafana
+
+import "testing"
+
+func TestAddGridPanelConvertsRows(t *testing.T) {
+	db := BuildHostDashboard("Hosts", "system", "influx", "host")
+	existing := GetDefaultPanel("Uptime", `SELECT last("uptime") FROM "system"`)
+	existing.ID = 100
+	existing.GridPos = &GridPos{H: 4, W: 24, X: 0, Y: 0}
+	db.Panels = []Panel{existing}
+	rowPanels := 0
+	for _, row := range db.Rows {
+		rowPanels += len(row.Panels)
+	}
+	if rowPanels == 0 {
+		t.Fatal("BuildHostDashboard returned no row panels")
+	}
+
+	db = AddGridPanel(db, GetDefaultPanel("Load", `SELECT 1`), 12, 8)
+
+	if db.SchemaVersion != 16 {
+		t.Errorf("schemaVersion = %d, want 16", db.SchemaVersion)
+	}
+	if len(db.Rows) != 0 {
+		t.Errorf("%d rows left, Grafana ignores them from schema 16", len(db.Rows))
+	}
+	if len(db.Panels) != 1+rowPanels+1 {
+		t.Fatalf("%d panels, want the grid panel, %d converted panels and the added one", len(db.Panels), rowPanels)
+	}
+	if db.Panels[0].ID != 100 || db.Panels[0].GridPos.Y != 0 {
+		t.Errorf("existing grid panel moved: id %d, y %d", db.Panels[0].ID, db.Panels[0].GridPos.Y)
+	}
+	ids := make(map[int]bool)
+	for i, panel := range db.Panels {
+		if ids[panel.ID] {
+			t.Errorf("duplicate panel id %d", panel.ID)
+		}
+		ids[panel.ID] = true
+		if i > 0 && panel.GridPos.Y < 4 {
+			t.Errorf("panel %q at y %d overlaps the existing grid panel", panel.Title, panel.GridPos.Y)
+		}
+	}
+	added := db.Panels[len(db.Panels)-1]
+	if added.Title != "Load" || added.ID != 101 {
+		t.Errorf("added panel %q has id %d, want \"Load\" with id 101", added.Title, added.ID)
+	}
+	if errs := ValidateDashboard(db); len(errs) > 0 {
+		t.Errorf("invalid dashboard: %v", errs)
+	}
+}
+
+func TestAddGridPanelKeepsGridDashboard(t *testing.T) {
+	db := *GetDefaultDashBoard("Grid")
+	db.Rows = make([]Row, 0)
+	db = AddGridPanel(db, GetDefaultPanel("A", `SELECT 1`), 12, 8)
+	db = AddGridPanel(db, GetDefaultPanel("B", `SELECT 2`), 12, 8)
+	if len(db.Panels) != 2 {
+		t.Fatalf("%d panels, want 2", len(db.Panels))
+	}
+	if a, b := db.Panels[0], db.Panels[1]; a.ID != 1 || b.ID != 2 || b.GridPos.X != 12 || b.GridPos.Y != 0 {
+		t.Errorf("panels laid out as %+v id %d and %+v id %d", *a.GridPos, a.ID, *b.GridPos, b.ID)
+	}
+}
+
+func TestAppendRowFollowsLayout(t *testing.T) {
+	legacy := AddTestDataPanel(*GetDefaultDashBoard("Rows"), "Walk", "random_walk")
+	if len(legacy.Panels) != 0 || len(legacy.Rows) == 0 {
+		t.Errorf("legacy dashboard got %d grid panels and %d rows", len(legacy.Panels), len(legacy.Rows))
+	}
+
+	db := *GetDefaultDashBoard("Grid")
+	db = AddGridPanel(db, GetDefaultPanel("A", `SELECT 1`), 6, 8)
+	db = AddTestDataPanel(db, "Walk", "random_walk")
+	if len(db.Rows) != 0 {
+		t.Errorf("%d legacy rows on a grid dashboard", len(db.Rows))
+	}
+	last := db.Panels[len(db.Panels)-1]
+	if last.Title != "Walk" || last.GridPos == nil || last.GridPos.X != 0 || last.GridPos.Y != gridBottom(db.Panels[:len(db.Panels)-1]) {
+		t.Errorf("TestData panel %q not on a new grid line: %+v", last.Title, last.GridPos)
+	}
+	if last.ID != maxPanelID(db) || last.ID == db.Panels[0].ID {
+		t.Errorf("TestData panel has id %d", last.ID)
+	}
+}
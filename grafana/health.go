@@ -0,0 +1,68 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// A HealthInfo contains the server information returned by /api/health
+type HealthInfo struct {
+	Commit   string `json:"commit"`
+	Database string `json:"database"`
+	Version  string `json:"version"`
+}
+
+// Health returns the version and database status of the Grafana server.
+func (s *Session) Health() (health HealthInfo, err error) {
+	reqURL := s.url + "/api/health"
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&health)
+	return
+}
+
+// schemaVersions maps the first Grafana release of a schema to the dashboard schema version it migrates to.
+var schemaVersions = []struct {
+	major, minor, schema int
+}{
+	{4, 0, 14},
+	{5, 0, 16},
+	{6, 0, 18},
+	{6, 4, 20},
+	{6, 5, 21},
+	{6, 7, 22},
+	{7, 0, 25},
+	{7, 1, 26},
+	{7, 3, 27},
+	{8, 0, 30},
+	{8, 2, 31},
+	{8, 3, 33},
+	{8, 4, 35},
+	{8, 5, 36},
+	{9, 1, 37},
+	{10, 0, 38},
+	{10, 4, 39},
+}
+
+// ServerSchemaVersion returns the dashboard schema version the server migrates dashboards to.
+// It is derived from the version reported by Health; servers older than 4.0 get 14.
+func (s *Session) ServerSchemaVersion() (int, error) {
+	health, err := s.Health()
+	if err != nil {
+		return 0, err
+	}
+	var major, minor int
+	if _, err := fmt.Sscanf(health.Version, "%d.%d", &major, &minor); err != nil {
+		return 0, GrafanaError{0, fmt.Sprintf("unable to parse Grafana version %q", health.Version)}
+	}
+	schema := schemaVersions[0].schema
+	for _, v := range schemaVersions {
+		if major > v.major || (major == v.major && minor >= v.minor) {
+			schema = v.schema
+		}
+	}
+	return schema, nil
+}
@@ -0,0 +1,69 @@
+package grafana
+
+// Color modes and scales of a heatmap panel
+const (
+	HeatmapModeSpectrum = "spectrum"
+	HeatmapModeOpacity  = "opacity"
+	HeatmapScaleLinear  = "linear"
+	HeatmapScaleSqrt    = "sqrt"
+)
+
+// A HeatmapColor contains how the buckets of a heatmap panel are colored
+type HeatmapColor struct {
+	CardColor   string  `json:"cardColor"`
+	ColorScale  string  `json:"colorScale"`
+	ColorScheme string  `json:"colorScheme"`
+	Exponent    float64 `json:"exponent"`
+	Mode        string  `json:"mode"`
+}
+
+// HeatmapOptions groups the settings of a heatmap panel.
+// Mode is "spectrum" to color buckets with ColorScheme or "opacity" to shade CardColor by Scale,
+// "sqrt" being more readable than "linear" on skewed distributions like latencies.
+type HeatmapOptions struct {
+	CardColor       string
+	ColorScheme     string
+	HideZeroBuckets bool
+	Mode            string
+	Scale           string
+}
+
+func GetDefaultHeatmapOptions() HeatmapOptions {
+	return HeatmapOptions{
+		CardColor:       "#b4ff00",
+		ColorScheme:     "interpolateOranges",
+		HideZeroBuckets: false,
+		Mode:            HeatmapModeSpectrum,
+		Scale:           HeatmapScaleSqrt,
+	}
+}
+
+// GetHeatmapPanel returns a heatmap panel of the series returned by influxql.
+// Invalid modes or scales in opts fall back to the default ones.
+func GetHeatmapPanel(title string, influxql string, opts HeatmapOptions) Panel {
+	defaults := GetDefaultHeatmapOptions()
+	if opts.Mode != HeatmapModeSpectrum && opts.Mode != HeatmapModeOpacity {
+		opts.Mode = defaults.Mode
+	}
+	if opts.Scale != HeatmapScaleLinear && opts.Scale != HeatmapScaleSqrt {
+		opts.Scale = defaults.Scale
+	}
+	if opts.CardColor == "" {
+		opts.CardColor = defaults.CardColor
+	}
+	if opts.ColorScheme == "" {
+		opts.ColorScheme = defaults.ColorScheme
+	}
+	panel := GetDefaultPanel(title, influxql)
+	panel.Type = "heatmap"
+	panel.DataFormat = "timeseries"
+	panel.HideZeroBuckets = opts.HideZeroBuckets
+	panel.Color = &HeatmapColor{
+		CardColor:   opts.CardColor,
+		ColorScale:  opts.Scale,
+		ColorScheme: opts.ColorScheme,
+		Exponent:    0.5,
+		Mode:        opts.Mode,
+	}
+	return panel
+}
@@ -0,0 +1,160 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// saveRawDashboard uploads a dashboard json as is into the folder, 0 being the General folder.
+// The id is cleared so the dashboard is matched by uid or title when overwriting.
+func (s *Session) saveRawDashboard(model json.RawMessage, folderID int, overwrite bool) (result DashboardSaveResult, err error) {
+	var dashboard map[string]interface{}
+	if err = json.Unmarshal(model, &dashboard); err != nil {
+		return
+	}
+	dashboard["id"] = nil
+	content := map[string]interface{}{
+		"dashboard": dashboard,
+		"folderId":  folderID,
+		"overwrite": overwrite,
+	}
+	reqURL := s.url + "/api/dashboards/db"
+	jsonStr, _ := json.Marshal(content)
+	body, err := s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	// as in SaveDashboard, an empty body is a successful save
+	if err = dec.Decode(&result); err == io.EOF {
+		err = nil
+	}
+	return
+}
+
+// ImportDirectory uploads every .json dashboard found under root with overwrite.
+// Files directly in root go to the General folder, files in a subdirectory go to the Grafana folder
+// named after it, which is created if needed. Failing files don't stop the import,
+// their errors are returned together with the results of the others.
+func (s *Session) ImportDirectory(root string) ([]DashboardSaveResult, error) {
+	if s.readOnly {
+		return nil, ErrReadOnly
+	}
+	folders, err := s.ListFolders()
+	if err != nil {
+		return nil, err
+	}
+	folderIDs := make(map[string]int)
+	for _, f := range folders {
+		folderIDs[f.Title] = f.ID
+	}
+	results := make([]DashboardSaveResult, 0)
+	errs := make([]error, 0)
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.ToLower(filepath.Ext(path)) != ".json" {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		folderID := 0
+		if parts := strings.Split(filepath.ToSlash(rel), "/"); len(parts) > 1 {
+			id, ok := folderIDs[parts[0]]
+			if !ok {
+				folder, err := s.CreateFolder(parts[0])
+				if err != nil {
+					errs = append(errs, GrafanaError{0, fmt.Sprintf("%s: %s", rel, err)})
+					return nil
+				}
+				id = folder.ID
+				folderIDs[parts[0]] = id
+			}
+			folderID = id
+		}
+		model, err := ioutil.ReadFile(path)
+		if err == nil {
+			var result DashboardSaveResult
+			result, err = s.saveRawDashboard(model, folderID, true)
+			if err == nil {
+				results = append(results, result)
+			}
+		}
+		if err != nil {
+			errs = append(errs, GrafanaError{0, fmt.Sprintf("%s: %s", rel, err)})
+		}
+		return nil
+	})
+	if err != nil {
+		return results, err
+	}
+	if len(errs) > 0 {
+		return results, joinErrors(errs)
+	}
+	return results, nil
+}
+
+// ImportWithInputs uploads a shareable dashboard, as exported by ExportShareable, through /api/dashboards/import.
+// inputs gives the datasource name or uid of every __inputs placeholder, e.g. {"DS_INFLUXDB": "influx-prod"};
+// the dashboard is refused if one is missing. An empty folderUID imports into the General folder.
+func (s *Session) ImportWithInputs(model json.RawMessage, inputs map[string]string, folderUID string, overwrite bool) (result DashboardSaveResult, err error) {
+	var dashboard struct {
+		Inputs []DashboardInput `json:"__inputs"`
+	}
+	if err = json.Unmarshal(model, &dashboard); err != nil {
+		return
+	}
+	type importInput struct {
+		Name     string `json:"name"`
+		Type     string `json:"type"`
+		PluginID string `json:"pluginId"`
+		Value    string `json:"value"`
+	}
+	values := make([]importInput, 0, len(dashboard.Inputs))
+	missing := make([]string, 0)
+	for _, in := range dashboard.Inputs {
+		value, ok := inputs[in.Name]
+		if !ok {
+			missing = append(missing, in.Name)
+			continue
+		}
+		values = append(values, importInput{Name: in.Name, Type: in.Type, PluginID: in.PluginID, Value: value})
+	}
+	if len(missing) > 0 {
+		return result, GrafanaError{0, "missing dashboard inputs: " + strings.Join(missing, ", ")}
+	}
+	content := map[string]interface{}{
+		"dashboard": model,
+		"inputs":    values,
+		"folderUid": folderUID,
+		"overwrite": overwrite,
+	}
+	reqURL := s.url + "/api/dashboards/import"
+	jsonStr, _ := json.Marshal(content)
+	body, err := s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	if err != nil {
+		return
+	}
+	var res struct {
+		DashboardID int    `json:"dashboardId"`
+		UID         string `json:"uid"`
+		ImportedURL string `json:"importedUrl"`
+		Slug        string `json:"slug"`
+		Imported    bool   `json:"imported"`
+	}
+	dec := json.NewDecoder(body)
+	if err = dec.Decode(&res); err != nil {
+		return
+	}
+	result = DashboardSaveResult{ID: res.DashboardID, UID: res.UID, URL: res.ImportedURL, Slug: res.Slug}
+	if res.Imported {
+		result.Status = "success"
+	}
+	return
+}
@@ -0,0 +1,259 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// An InfluxResponse contains the json returned by InfluxDB for a query
+type InfluxResponse struct {
+	Results []InfluxResult `json:"results"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// An InfluxResult contains the result of one statement of an InfluxDB query
+type InfluxResult struct {
+	StatementID int            `json:"statement_id"`
+	Series      []InfluxSeries `json:"series"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// An InfluxSeries contains the rows of one series returned by InfluxDB
+type InfluxSeries struct {
+	Name    string            `json:"name"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	Columns []string          `json:"columns"`
+	Values  [][]interface{}   `json:"values"`
+}
+
+// queryInfluxDB runs influxql against database through the Grafana proxy of the datasource.
+// Errors reported by InfluxDB inside the response are returned as GrafanaError.
+func (s *Session) queryInfluxDB(datasourceID int, database, influxql string) (res InfluxResponse, err error) {
+	query := url.Values{}
+	query.Set("db", database)
+	query.Set("q", influxql)
+	query.Set("epoch", "ms")
+	reqURL := fmt.Sprintf("%s/api/datasources/proxy/%d/query?%s", s.url, datasourceID, query.Encode())
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	if err = dec.Decode(&res); err != nil {
+		return
+	}
+	if res.Error != "" {
+		return res, GrafanaError{0, res.Error}
+	}
+	for _, r := range res.Results {
+		if r.Error != "" {
+			return res, GrafanaError{0, r.Error}
+		}
+	}
+	return
+}
+
+// firstColumn returns the first column of every row as a string, e.g. the keys of SHOW TAG KEYS.
+func (r InfluxResponse) firstColumn() []string {
+	values := make([]string, 0)
+	for _, result := range r.Results {
+		for _, series := range result.Series {
+			for _, row := range series.Values {
+				if len(row) > 0 {
+					values = append(values, fmt.Sprint(row[0]))
+				}
+			}
+		}
+	}
+	return values
+}
+
+// AddTemplatingFromMeasurement creates a template variable for every tag key of the measurement.
+// The tag keys are discovered with SHOW TAG KEYS through the datasource proxy.
+func (s *Session) AddTemplatingFromMeasurement(db Dashboard, measurementName, datasource string, datasourceID int) (Dashboard, error) {
+	ds, err := s.GetDataSource(datasourceID)
+	if err != nil {
+		return db, err
+	}
+	res, err := s.queryInfluxDB(datasourceID, ds.Database, fmt.Sprintf(`SHOW TAG KEYS FROM "%s"`, measurementName))
+	if err != nil {
+		return db, err
+	}
+	return s.AddTemplating(db, res.firstColumn(), measurementName, datasource), nil
+}
+
+// A QueryResult contains the series returned by each target of a panel, keyed by refId
+type QueryResult struct {
+	Series map[string][]InfluxSeries
+}
+
+// defaultQueryInterval replaces $interval in raw queries run outside of Grafana.
+const defaultQueryInterval = "1m"
+
+// influxTime converts a Grafana time like "now-6h", "now" or an RFC3339 date to an InfluxQL expression.
+func influxTime(t string) string {
+	if t == "now" {
+		return "now()"
+	}
+	if strings.HasPrefix(t, "now-") {
+		return "now() - " + strings.TrimPrefix(t, "now-")
+	}
+	return "'" + t + "'"
+}
+
+// findDataSource returns the datasource a panel refers to, by name or by the uid of a {"type", "uid"}
+// object, and the default one for an empty reference.
+func (s *Session) findDataSource(ref interface{}) (ds DataSource, err error) {
+	name, _ := ref.(string)
+	var uid string
+	if obj, ok := ref.(map[string]interface{}); ok {
+		uid, _ = obj["uid"].(string)
+	}
+	dataSources, err := s.ListDataSources()
+	if err != nil {
+		return
+	}
+	for _, d := range dataSources {
+		switch {
+		case uid != "" && d.UID == uid,
+			uid == "" && name != "" && d.Name == name,
+			uid == "" && name == "" && d.IsDefault:
+			return d, nil
+		}
+	}
+	if uid != "" {
+		return ds, GrafanaError{0, fmt.Sprintf("datasource with uid %q not found", uid)}
+	}
+	return ds, GrafanaError{0, fmt.Sprintf("datasource %q not found", name)}
+}
+
+// QueryPanel runs the raw InfluxQL targets of a panel between from and to and returns their series.
+// The panel may be in a row, on the grid or nested in a collapsed row.
+// Template variables are not substituted, $timeFilter and $interval are.
+func (s *Session) QueryPanel(uid string, panelID int, from, to string) (result QueryResult, err error) {
+	dashRes, err := s.GetDashboardByUID(uid)
+	if err != nil {
+		return
+	}
+	var panel *Panel
+	panels := allPanels(dashRes.Model)
+	for i := range panels {
+		if panels[i].ID == panelID && panels[i].Type != "row" {
+			panel = &panels[i]
+		}
+	}
+	if panel == nil {
+		return result, GrafanaError{0, fmt.Sprintf("panel %d not found in dashboard %s", panelID, uid)}
+	}
+	ds, err := s.findDataSource(panel.Datasource)
+	if err != nil {
+		return
+	}
+	timeFilter := fmt.Sprintf("time >= %s AND time <= %s", influxTime(from), influxTime(to))
+	replacer := strings.NewReplacer("$timeFilter", timeFilter, "$__interval", defaultQueryInterval, "$interval", defaultQueryInterval)
+	result.Series = make(map[string][]InfluxSeries)
+	for _, target := range panel.Targets {
+		res, err := s.queryInfluxDB(ds.ID, ds.Database, replacer.Replace(target.Query))
+		if err != nil {
+			return result, err
+		}
+		for _, r := range res.Results {
+			result.Series[target.RefID] = append(result.Series[target.RefID], r.Series...)
+		}
+	}
+	return
+}
+
+// valueColumn returns the "value" column of every row when there is one, as SHOW TAG VALUES returns key and value,
+// and the first column otherwise.
+func (r InfluxResponse) valueColumn() []string {
+	values := make([]string, 0)
+	for _, result := range r.Results {
+		for _, series := range result.Series {
+			col := 0
+			for i, name := range series.Columns {
+				if name == "value" {
+					col = i
+				}
+			}
+			for _, row := range series.Values {
+				if len(row) > col {
+					values = append(values, fmt.Sprint(row[col]))
+				}
+			}
+		}
+	}
+	return values
+}
+
+// PreviewTemplateValues runs the query of the template through the datasource proxy and returns the values
+// the dropdown would show, after the template regex is applied.
+func (s *Session) PreviewTemplateValues(t Template, datasourceID int) (values []string, err error) {
+	ds, err := s.GetDataSource(datasourceID)
+	if err != nil {
+		return
+	}
+	res, err := s.queryInfluxDB(datasourceID, ds.Database, t.Query)
+	if err != nil {
+		return
+	}
+	values = res.valueColumn()
+	if t.Regex == "" {
+		return
+	}
+	re, err := compileTemplateRegex(t.Regex)
+	if err != nil {
+		return nil, GrafanaError{0, fmt.Sprintf("template %q has invalid regex: %s", t.Name, err)}
+	}
+	filtered := make([]string, 0, len(values))
+	for _, v := range values {
+		m := re.FindStringSubmatch(v)
+		if m == nil {
+			continue
+		}
+		if len(m) > 1 {
+			filtered = append(filtered, m[1])
+		} else {
+			filtered = append(filtered, m[0])
+		}
+	}
+	return filtered, nil
+}
+
+// A QueryCost contains the size of the result of a query
+type QueryCost struct {
+	Series int
+	// Points counts the non null values, the time column excluded
+	Points int
+}
+
+// EstimateQueryCost runs influxql through the datasource proxy and measures its result,
+// e.g. to flag queries returning too many points for a browser. $timeFilter and $interval
+// are replaced as for the default dashboard time range, the last 6 hours.
+func (s *Session) EstimateQueryCost(datasourceID int, influxql string) (cost QueryCost, err error) {
+	ds, err := s.GetDataSource(datasourceID)
+	if err != nil {
+		return
+	}
+	timeFilter := fmt.Sprintf("time >= %s AND time <= %s", influxTime("now-6h"), influxTime("now"))
+	replacer := strings.NewReplacer("$timeFilter", timeFilter, "$__interval", defaultQueryInterval, "$interval", defaultQueryInterval)
+	res, err := s.queryInfluxDB(datasourceID, ds.Database, replacer.Replace(influxql))
+	if err != nil {
+		return
+	}
+	for _, result := range res.Results {
+		cost.Series += len(result.Series)
+		for _, series := range result.Series {
+			for _, row := range series.Values {
+				for i, v := range row {
+					if v != nil && (i >= len(series.Columns) || series.Columns[i] != "time") {
+						cost.Points++
+					}
+				}
+			}
+		}
+	}
+	return
+}
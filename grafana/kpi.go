@@ -0,0 +1,65 @@
+package grafana
+
+import (
+	"sort"
+)
+
+// A KPISpec describes a single value panel of a KPI row.
+// Thresholds turn the value orange from the first one and red from the last one.
+type KPISpec struct {
+	Title      string
+	Query      string
+	Unit       string
+	Thresholds []float64
+}
+
+// getKPIPanel returns a stat panel showing the last value of the query of kpi.
+func getKPIPanel(kpi KPISpec) Panel {
+	panel := GetDefaultPanel(kpi.Title, kpi.Query)
+	panel.Type = "stat"
+	limits := make([]float64, len(kpi.Thresholds))
+	copy(limits, kpi.Thresholds)
+	sort.Float64s(limits)
+	steps := []ThresholdStep{{Color: "green"}}
+	for i := range limits {
+		color := "orange"
+		if i == len(limits)-1 {
+			color = "red"
+		}
+		steps = append(steps, ThresholdStep{Color: color, Value: &limits[i]})
+	}
+	panel.FieldConfig = &FieldConfig{
+		Defaults: FieldDefaults{
+			Thresholds: &Thresholds{Mode: "absolute", Steps: steps},
+			Unit:       kpi.Unit,
+		},
+		Overrides: make([]interface{}, 0),
+	}
+	panel = SetPanelOption(panel, "colorMode", "value")
+	panel = SetPanelOption(panel, "reduceOptions", map[string]interface{}{
+		"calcs":  []string{"lastNotNull"},
+		"fields": "",
+		"values": false,
+	})
+	return panel
+}
+
+// AddKPIRow adds a row of stat panels sharing its width evenly, e.g. requests per second, error rate
+// and latency of a service overview. The dashboard is returned unchanged if kpis is empty or has more
+// than 12 entries, which wouldn't fit in a row. On grid dashboards the panels take a new grid line,
+// about 24/len(kpis) columns each.
+func AddKPIRow(db Dashboard, kpis []KPISpec) Dashboard {
+	if len(kpis) == 0 || len(kpis) > rowSpan {
+		return db
+	}
+	row := GetDefaultRow("", "")
+	row.Height = "150px"
+	row.Panels = make([]Panel, 0, len(kpis))
+	weights := make([]int, 0, len(kpis))
+	for _, kpi := range kpis {
+		row.Panels = append(row.Panels, getKPIPanel(kpi))
+		weights = append(weights, 1)
+	}
+	row = SetRowPanelWeights(row, weights)
+	return appendRow(db, row)
+}
@@ -0,0 +1,66 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// A LibraryPanel contains a panel shared between dashboards, Model being the panel json
+type LibraryPanel struct {
+	ID          int             `json:"id"`
+	UID         string          `json:"uid"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	FolderUID   string          `json:"folderUid"`
+	Model       json.RawMessage `json:"model"`
+	Version     int             `json:"version"`
+}
+
+// A LibraryRef is the reference a dashboard panel keeps to its library panel
+type LibraryRef struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+}
+
+// ListLibraryPanels returns the library panels of the folder, or of every folder if folderUID is empty.
+func (s *Session) ListLibraryPanels(folderUID string) ([]LibraryPanel, error) {
+	panels := make([]LibraryPanel, 0)
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("kind", "1")
+		query.Set("perPage", "100")
+		query.Set("page", fmt.Sprint(page))
+		if folderUID != "" {
+			query.Set("folderFilterUIDs", folderUID)
+		}
+		reqURL := s.url + "/api/library-elements?" + query.Encode()
+		body, err := s.httpRequest("GET", reqURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		var res struct {
+			Result struct {
+				TotalCount int            `json:"totalCount"`
+				Elements   []LibraryPanel `json:"elements"`
+			} `json:"result"`
+		}
+		dec := json.NewDecoder(body)
+		if err = dec.Decode(&res); err != nil {
+			return nil, err
+		}
+		panels = append(panels, res.Result.Elements...)
+		if len(res.Result.Elements) == 0 || len(panels) >= res.Result.TotalCount {
+			return panels, nil
+		}
+	}
+}
+
+// GetLibraryPanelRef returns a panel that only references the library panel, so dashboards embedding it
+// follow its updates. Place it with AddGridPanel.
+func GetLibraryPanelRef(lp LibraryPanel) Panel {
+	return Panel{
+		LibraryPanel: &LibraryRef{UID: lp.UID, Name: lp.Name},
+		Title:        lp.Name,
+	}
+}
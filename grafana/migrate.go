@@ -0,0 +1,161 @@
+package grafana
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A GridPos is the position of a panel in the grid layout of schema 16 and later dashboards
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// gridColumns is the number of columns of the grid layout, twice the columns of a row.
+const gridColumns = 24
+
+// gridCellHeight is the height in pixels of a grid cell, margin included.
+const gridCellHeight = 38
+
+// gridHeight converts a row height like "250px" to grid cells, as Grafana does.
+func gridHeight(height string) int {
+	px, err := strconv.Atoi(strings.TrimSuffix(height, "px"))
+	if err != nil || px <= 0 {
+		px = 250
+	}
+	return (px + gridCellHeight - 1) / gridCellHeight
+}
+
+// gridBottom returns the first grid line below the panels.
+func gridBottom(panels []Panel) int {
+	bottom := 0
+	for _, panel := range panels {
+		if panel.GridPos != nil && panel.GridPos.Y+panel.GridPos.H > bottom {
+			bottom = panel.GridPos.Y + panel.GridPos.H
+		}
+	}
+	return bottom
+}
+
+// shiftGridPos returns a copy of pos moved down by lines, nil staying nil.
+func shiftGridPos(pos *GridPos, lines int) *GridPos {
+	if pos == nil {
+		return nil
+	}
+	shifted := *pos
+	shifted.Y += lines
+	return &shifted
+}
+
+// rowsToGrid lays out the panels of rows on the grid from line y, numbering panels without id after lastID.
+// Rows with a title, collapsed or repeated become row panels, the panels of a collapsed row are nested in it.
+func rowsToGrid(rows []Row, lastID, y int) []Panel {
+	panels := make([]Panel, 0)
+	for _, row := range rows {
+		h := gridHeight(row.Height)
+		var rowPanel *Panel
+		if row.ShowTitle || row.Collapse || row.Repeat != nil {
+			lastID++
+			rowPanel = &Panel{ID: lastID, Type: "row", Title: row.Title, Collapsed: row.Collapse, Panels: make([]Panel, 0)}
+			rowPanel.GridPos = &GridPos{H: 1, W: gridColumns, X: 0, Y: y}
+			if repeat, ok := row.Repeat.(string); ok {
+				rowPanel.Repeat = repeat
+			}
+			y++
+		}
+		x, start := 0, y
+		for _, panel := range row.Panels {
+			w := panel.Span * gridColumns / rowSpan
+			if w <= 0 || w > gridColumns {
+				w = gridColumns
+			}
+			if x+w > gridColumns {
+				x = 0
+				y += h
+			}
+			if panel.ID == 0 {
+				lastID++
+				panel.ID = lastID
+			}
+			panel.GridPos = &GridPos{H: h, W: w, X: x, Y: y}
+			x += w
+			if row.Collapse {
+				rowPanel.Panels = append(rowPanel.Panels, panel)
+			} else {
+				panels = append(panels, panel)
+			}
+		}
+		if rowPanel != nil {
+			panels = append(panels, *rowPanel)
+		}
+		if row.Collapse {
+			y = start
+		} else if len(row.Panels) > 0 {
+			y += h
+		}
+	}
+	return panels
+}
+
+// graphToTimeSeries converts a graph panel to a timeseries panel, keeping its fill, line width,
+// stepped lines and connected null points.
+func graphToTimeSeries(p Panel) Panel {
+	if p.Type != "graph" {
+		return p
+	}
+	p.Type = "timeseries"
+	fc := getFieldConfig(p)
+	custom := getFieldCustom(fc)
+	custom.FillOpacity = p.Fill * 10
+	custom.LineWidth = p.Linewidth
+	custom.SpanNulls = p.NullPointMode == string(NullPointModeConnected)
+	if p.SteppedLine {
+		custom.LineInterpolation = "stepAfter"
+	}
+	fc.Defaults.Custom = &custom
+	p.FieldConfig = &fc
+	return p
+}
+
+// rowsToPanels moves the legacy rows of the dashboard to the grid layout, below its grid panels.
+func rowsToPanels(db Dashboard) Dashboard {
+	converted := rowsToGrid(db.Rows, maxPanelID(db), gridBottom(db.Panels))
+	panels := make([]Panel, 0, len(db.Panels)+len(converted))
+	panels = append(panels, db.Panels...)
+	db.Panels = append(panels, converted...)
+	db.Rows = make([]Row, 0)
+	return db
+}
+
+// MigrateSchema upgrades the dashboard to the target schema version so that it round-trips unchanged
+// through a newer Grafana. From schema 16 rows are converted to the grid layout, and from schema 30
+// (Grafana 8) graph panels become timeseries panels. Other migrations only bump the version.
+// Grid panels the dashboard already has are kept, the converted rows being laid out below them.
+func MigrateSchema(db Dashboard, targetVersion int) (Dashboard, error) {
+	if targetVersion < db.SchemaVersion {
+		return db, GrafanaError{0, fmt.Sprintf("can't migrate schema %d down to %d", db.SchemaVersion, targetVersion)}
+	}
+	if db.SchemaVersion < 16 && targetVersion >= 16 {
+		db = rowsToPanels(db)
+	}
+	if targetVersion >= 30 {
+		panels := make([]Panel, len(db.Panels))
+		for i, panel := range db.Panels {
+			panel = graphToTimeSeries(panel)
+			if len(panel.Panels) > 0 {
+				nested := make([]Panel, len(panel.Panels))
+				for j, p := range panel.Panels {
+					nested[j] = graphToTimeSeries(p)
+				}
+				panel.Panels = nested
+			}
+			panels[i] = panel
+		}
+		db.Panels = panels
+	}
+	db.SchemaVersion = targetVersion
+	return db, nil
+}
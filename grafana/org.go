@@ -0,0 +1,117 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Organization roles
+const (
+	RoleViewer = "Viewer"
+	RoleEditor = "Editor"
+	RoleAdmin  = "Admin"
+)
+
+// An OrgUser contains a user of the current organization and its role
+type OrgUser struct {
+	UserID int    `json:"userId"`
+	Login  string `json:"login"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+
+func validRole(role string) bool {
+	return role == RoleViewer || role == RoleEditor || role == RoleAdmin
+}
+
+// ListOrgUsers returns the users of the current organization with their role.
+func (s *Session) ListOrgUsers() (users []OrgUser, err error) {
+	reqURL := s.url + "/api/org/users"
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&users)
+	return
+}
+
+// UpdateOrgUserRole changes the role of a user in the current organization.
+func (s *Session) UpdateOrgUserRole(userID int, role string) (err error) {
+	if !validRole(role) {
+		return GrafanaError{0, fmt.Sprintf("invalid role %q", role)}
+	}
+	reqURL := fmt.Sprintf("%s/api/org/users/%d", s.url, userID)
+	jsonStr, _ := json.Marshal(map[string]string{"role": role})
+	_, err = s.httpRequest("PATCH", reqURL, bytes.NewBuffer(jsonStr))
+	return
+}
+
+// OrgPreferences contains the defaults of the current organization, used by users who didn't set their own
+type OrgPreferences struct {
+	HomeDashboardID  int    `json:"homeDashboardId"`
+	HomeDashboardUID string `json:"homeDashboardUID,omitempty"`
+	Theme            string `json:"theme"`
+	Timezone         string `json:"timezone"`
+	WeekStart        string `json:"weekStart,omitempty"`
+}
+
+// GetOrgPreferences returns the preferences of the current organization.
+func (s *Session) GetOrgPreferences() (prefs OrgPreferences, err error) {
+	reqURL := s.url + "/api/org/preferences"
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&prefs)
+	return
+}
+
+// SetOrgHomeDashboard makes the dashboard the landing page of the organization members.
+// The other preferences are kept.
+func (s *Session) SetOrgHomeDashboard(uid string) (err error) {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	prefs, err := s.GetOrgPreferences()
+	if err != nil {
+		return
+	}
+	prefs.HomeDashboardID = 0
+	prefs.HomeDashboardUID = uid
+	return s.SetOrgPreferences(prefs)
+}
+
+// Themes of the Grafana UI, an empty theme uses the server default
+const (
+	ThemeDark  = "dark"
+	ThemeLight = "light"
+)
+
+// validatePreferences checks the theme, timezone and week start of org or user preferences, empty ones being valid.
+func validatePreferences(theme, timezone, weekStart string) error {
+	if theme != "" && theme != ThemeDark && theme != ThemeLight {
+		return GrafanaError{0, fmt.Sprintf("invalid theme %q", theme)}
+	}
+	if timezone != "" && !ValidTimezone(timezone) {
+		return GrafanaError{0, fmt.Sprintf("invalid timezone %q", timezone)}
+	}
+	if !validWeekStart(weekStart) {
+		return GrafanaError{0, fmt.Sprintf("invalid week start %q", weekStart)}
+	}
+	return nil
+}
+
+// SetOrgPreferences replaces the preferences of the current organization.
+// Empty theme, timezone and week start fall back to the server defaults.
+func (s *Session) SetOrgPreferences(prefs OrgPreferences) (err error) {
+	if err = validatePreferences(prefs.Theme, prefs.Timezone, prefs.WeekStart); err != nil {
+		return
+	}
+	reqURL := s.url + "/api/org/preferences"
+	jsonStr, _ := json.Marshal(prefs)
+	_, err = s.httpRequest("PUT", reqURL, bytes.NewBuffer(jsonStr))
+	return
+}
@@ -0,0 +1,30 @@
+package grafana
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// A Plugin contains the description of an installed Grafana plugin
+type Plugin struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+// ListPlugins returns the installed plugins of the given type ("panel", "datasource", "app").
+// An empty typeFilter lists all plugins.
+func (s *Session) ListPlugins(typeFilter string) (plugins []Plugin, err error) {
+	reqURL := s.url + "/api/plugins"
+	if typeFilter != "" {
+		reqURL += "?type=" + url.QueryEscape(typeFilter)
+	}
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&plugins)
+	return
+}
@@ -0,0 +1,86 @@
+package grafana
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"sort"
+)
+
+// addVars appends the template variable values to query as var-<name> parameters.
+func addVars(query url.Values, vars map[string]string) {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		query.Add("var-"+name, vars[name])
+	}
+}
+
+// RenderPanel renders a single panel of the dashboard identified by slug as a PNG image.
+// vars pins template variable values, otherwise the dashboard defaults are used.
+func (s *Session) RenderPanel(slug string, panelID, width, height int, vars map[string]string) ([]byte, error) {
+	query := url.Values{}
+	query.Set("panelId", fmt.Sprint(panelID))
+	query.Set("width", fmt.Sprint(width))
+	query.Set("height", fmt.Sprint(height))
+	addVars(query, vars)
+	reqURL := fmt.Sprintf("%s/render/dashboard-solo/db/%s?%s", s.url, slug, query.Encode())
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(body)
+}
+
+// Kiosk modes of a dashboard url: full hides all the Grafana chrome, tv keeps the dashboard title and controls
+const (
+	KioskFull = "full"
+	KioskTV   = "tv"
+)
+
+// URLOptions are the view settings of a dashboard url, empty ones keeping the dashboard defaults
+type URLOptions struct {
+	Kiosk   string
+	Refresh string
+	From    string
+	To      string
+	Theme   string
+	Vars    map[string]string
+}
+
+// DashboardURL returns a direct link to the dashboard, e.g. with kiosk mode and a refresh for wall displays.
+// Variable values are url encoded, so they may contain spaces or regex characters.
+func (s *Session) DashboardURL(uid string, opts URLOptions) string {
+	query := url.Values{}
+	if opts.Kiosk == KioskTV {
+		query.Set("kiosk", KioskTV)
+	}
+	if opts.Refresh != "" {
+		query.Set("refresh", opts.Refresh)
+	}
+	if opts.From != "" {
+		query.Set("from", opts.From)
+	}
+	if opts.To != "" {
+		query.Set("to", opts.To)
+	}
+	if opts.Theme != "" {
+		query.Set("theme", opts.Theme)
+	}
+	addVars(query, opts.Vars)
+	params := query.Encode()
+	if opts.Kiosk == KioskFull {
+		if params != "" {
+			params += "&"
+		}
+		params += "kiosk"
+	}
+	link := s.url + "/d/" + url.PathEscape(uid)
+	if params != "" {
+		link += "?" + params
+	}
+	return link
+}
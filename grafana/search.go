@@ -0,0 +1,144 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+// A SearchResult contains a dashboard or folder found by the search API
+type SearchResult struct {
+	ID          int      `json:"id"`
+	UID         string   `json:"uid"`
+	Title       string   `json:"title"`
+	URL         string   `json:"url"`
+	Type        string   `json:"type"`
+	Tags        []string `json:"tags"`
+	IsStarred   bool     `json:"isStarred"`
+	FolderID    int      `json:"folderId,omitempty"`
+	FolderUID   string   `json:"folderUid,omitempty"`
+	FolderTitle string   `json:"folderTitle,omitempty"`
+}
+
+// search runs a query against /api/search, e.g. with type=dash-db to find only dashboards.
+func (s *Session) search(query url.Values) (results []SearchResult, err error) {
+	reqURL := s.url + "/api/search?" + query.Encode()
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&results)
+	return
+}
+
+// GetDashboardByTitle returns the dashboard with exactly the given title, found with the search API
+// so that it doesn't depend on how Grafana slugifies titles. A missing dashboard gives a 404 GrafanaError.
+func (s *Session) GetDashboardByTitle(title string) (dashboard DashboardResult, err error) {
+	query := url.Values{}
+	query.Set("query", title)
+	query.Set("type", "dash-db")
+	results, err := s.search(query)
+	if err != nil {
+		return
+	}
+	for _, res := range results {
+		if res.Title != title {
+			continue
+		}
+		if res.UID == "" {
+			// servers without uids link dashboards as /dashboard/db/<slug>
+			return s.GetDashboardBySlug(path.Base(res.URL))
+		}
+		return s.GetDashboardByUID(res.UID)
+	}
+	return dashboard, GrafanaError{http.StatusNotFound, fmt.Sprintf("dashboard %q not found", title)}
+}
+
+// ListStarredDashboards returns the dashboards starred by the session user.
+func (s *Session) ListStarredDashboards() ([]SearchResult, error) {
+	query := url.Values{}
+	query.Set("starred", "true")
+	query.Set("type", "dash-db")
+	return s.search(query)
+}
+
+// A TagCount contains a dashboard tag and the number of dashboards using it
+type TagCount struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
+}
+
+// ListTags returns every tag applied to dashboards of the current organization with its usage count.
+func (s *Session) ListTags() (tags []TagCount, err error) {
+	reqURL := s.url + "/api/dashboards/tags"
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&tags)
+	return
+}
+
+// FindDuplicateDashboards returns the dashboards sharing their title with another one, grouped by title.
+func (s *Session) FindDuplicateDashboards() (map[string][]SearchResult, error) {
+	query := url.Values{}
+	query.Set("type", "dash-db")
+	results, err := s.search(query)
+	if err != nil {
+		return nil, err
+	}
+	byTitle := make(map[string][]SearchResult)
+	for _, res := range results {
+		byTitle[res.Title] = append(byTitle[res.Title], res)
+	}
+	for title, group := range byTitle {
+		if len(group) < 2 {
+			delete(byTitle, title)
+		}
+	}
+	return byTitle, nil
+}
+
+// DeduplicateDashboards deletes duplicate dashboards, keeping in each group the one whose uid keep returns.
+// Groups where keep returns an unknown uid are left untouched and reported in the error.
+// It returns the number of dashboards deleted.
+func (s *Session) DeduplicateDashboards(keep func([]SearchResult) string) (int, error) {
+	if s.readOnly {
+		return 0, ErrReadOnly
+	}
+	duplicates, err := s.FindDuplicateDashboards()
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	errs := make([]error, 0)
+	for title, group := range duplicates {
+		kept := keep(group)
+		found := false
+		for _, res := range group {
+			found = found || res.UID == kept
+		}
+		if !found {
+			errs = append(errs, GrafanaError{0, fmt.Sprintf("dashboard %q: uid %q to keep is not a duplicate", title, kept)})
+			continue
+		}
+		for _, res := range group {
+			if res.UID == kept {
+				continue
+			}
+			if err := s.DeleteDashboardByUID(res.UID); err != nil {
+				errs = append(errs, GrafanaError{0, fmt.Sprintf("dashboard %q (%s): %s", title, res.UID, err)})
+				continue
+			}
+			deleted++
+		}
+	}
+	if len(errs) > 0 {
+		return deleted, joinErrors(errs)
+	}
+	return deleted, nil
+}
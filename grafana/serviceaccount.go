@@ -0,0 +1,43 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// CreateServiceAccount creates a service account with the given organization role and returns its id.
+func (s *Session) CreateServiceAccount(name, role string) (id int, err error) {
+	if !validRole(role) {
+		return 0, GrafanaError{0, fmt.Sprintf("invalid role %q", role)}
+	}
+	reqURL := s.url + "/api/serviceaccounts"
+	jsonStr, _ := json.Marshal(map[string]string{"name": name, "role": role})
+	body, err := s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	if err != nil {
+		return
+	}
+	var res struct {
+		ID int `json:"id"`
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&res)
+	return res.ID, err
+}
+
+// CreateServiceAccountToken creates a token for the service account and returns it.
+// Grafana only returns the token at creation, it can't be read back later.
+func (s *Session) CreateServiceAccountToken(saID int, name string) (token string, err error) {
+	reqURL := fmt.Sprintf("%s/api/serviceaccounts/%d/tokens", s.url, saID)
+	jsonStr, _ := json.Marshal(map[string]string{"name": name})
+	body, err := s.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr))
+	if err != nil {
+		return
+	}
+	var res struct {
+		Key string `json:"key"`
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&res)
+	return res.Key, err
+}
@@ -0,0 +1,46 @@
+package grafana
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestSessionConcurrentRequests is meant for go test -race: a Session may be shared by goroutines.
+func TestSessionConcurrentRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/health":
+			w.Write([]byte(`{"database": "ok", "version": "9.5.0"}`))
+		case "/api/datasources":
+			w.Write([]byte(`[{"id": 1, "uid": "abc", "name": "influx", "type": "influxdb"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	s := NewSession("", "", server.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Health(); err != nil {
+				t.Error(err)
+			}
+			s.LastResponse()
+			uids, err := s.DataSourceUIDMap()
+			if err != nil {
+				t.Error(err)
+			} else if uids["influx"] != "abc" {
+				t.Errorf("uid of influx = %q, want %q", uids["influx"], "abc")
+			}
+		}()
+	}
+	wg.Wait()
+	if code := s.LastResponse().StatusCode; code != http.StatusOK {
+		t.Errorf("last status = %d, want 200", code)
+	}
+}
@@ -0,0 +1,64 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// A Snapshot contains the description of a dashboard snapshot
+type Snapshot struct {
+	ID       int       `json:"id"`
+	Key      string    `json:"key"`
+	Name     string    `json:"name"`
+	External bool      `json:"external"`
+	Expires  time.Time `json:"expires"`
+	Created  time.Time `json:"created"`
+}
+
+// ListSnapshots returns the snapshots of the current organization.
+func (s *Session) ListSnapshots() (snapshots []Snapshot, err error) {
+	reqURL := s.url + "/api/dashboard/snapshots"
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&snapshots)
+	return
+}
+
+// DeleteSnapshot deletes the snapshot with the given key.
+func (s *Session) DeleteSnapshot(key string) (err error) {
+	reqURL := s.url + "/api/snapshots/" + key
+	_, err = s.httpRequest("DELETE", reqURL, nil)
+	return
+}
+
+// DeleteExpiredSnapshots deletes every snapshot past its expiry and returns how many were deleted.
+// A failed delete doesn't stop the others, the failures are returned together as one error.
+func (s *Session) DeleteExpiredSnapshots() (deleted int, err error) {
+	if s.readOnly {
+		return 0, ErrReadOnly
+	}
+	snapshots, err := s.ListSnapshots()
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	errs := make([]error, 0)
+	for _, snapshot := range snapshots {
+		if snapshot.Expires.IsZero() || snapshot.Expires.After(now) {
+			continue
+		}
+		if err := s.DeleteSnapshot(snapshot.Key); err != nil {
+			errs = append(errs, GrafanaError{0, fmt.Sprintf("snapshot %s: %s", snapshot.Key, err)})
+			continue
+		}
+		deleted++
+	}
+	if len(errs) > 0 {
+		return deleted, joinErrors(errs)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,130 @@
+package grafana
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// A DashboardSpec is a compact description of a dashboard, expanded by BuildFromSpec.
+// The json and yaml tags let specs be kept in files, e.g. checked into git.
+type DashboardSpec struct {
+	Title string `json:"title" yaml:"title"`
+	// Datasource is used by every variable and panel, empty for the default datasource
+	Datasource string      `json:"datasource,omitempty" yaml:"datasource,omitempty"`
+	TimeRange  Time        `json:"timeRange" yaml:"timeRange"`
+	Variables  []VarSpec   `json:"variables,omitempty" yaml:"variables,omitempty"`
+	Panels     []PanelSpec `json:"panels" yaml:"panels"`
+}
+
+// A VarSpec describes a query template variable
+type VarSpec struct {
+	Name       string `json:"name" yaml:"name"`
+	Label      string `json:"label,omitempty" yaml:"label,omitempty"`
+	Query      string `json:"query" yaml:"query"`
+	Multi      bool   `json:"multi,omitempty" yaml:"multi,omitempty"`
+	IncludeAll bool   `json:"includeAll,omitempty" yaml:"includeAll,omitempty"`
+}
+
+// A PanelSpec describes a panel with a single InfluxQL query.
+// Type is "graph", "timeseries" or "heatmap", empty meaning "graph", and Unit a Grafana unit like "bytes".
+type PanelSpec struct {
+	Title string `json:"title" yaml:"title"`
+	Query string `json:"query" yaml:"query"`
+	Type  string `json:"type,omitempty" yaml:"type,omitempty"`
+	Unit  string `json:"unit,omitempty" yaml:"unit,omitempty"`
+}
+
+// buildPanel returns the panel described by spec.
+func buildPanel(spec PanelSpec) (Panel, error) {
+	var panel Panel
+	switch spec.Type {
+	case "", "graph":
+		panel = GetDefaultPanel(spec.Title, spec.Query)
+		if spec.Unit != "" {
+			panel.Yaxes[0].Format = spec.Unit
+		}
+		return panel, nil
+	case "timeseries":
+		panel = GetTimeSeriesPanel(spec.Title, spec.Query)
+	case "heatmap":
+		panel = GetHeatmapPanel(spec.Title, spec.Query, GetDefaultHeatmapOptions())
+	default:
+		return panel, GrafanaError{0, fmt.Sprintf("panel %q has unknown type %q", spec.Title, spec.Type)}
+	}
+	if spec.Unit != "" {
+		fc := getFieldConfig(panel)
+		fc.Defaults.Unit = spec.Unit
+		panel.FieldConfig = &fc
+	}
+	return panel, nil
+}
+
+// BuildFromSpec builds the dashboard described by spec, each panel in its own row and numbered in order.
+// Every problem of the spec is returned together, e.g. a missing title and a panel without query.
+func BuildFromSpec(spec DashboardSpec) (Dashboard, error) {
+	errs := make([]error, 0)
+	if spec.Title == "" {
+		errs = append(errs, GrafanaError{0, "dashboard title is empty"})
+	}
+	db := *GetDefaultDashBoard(spec.Title)
+	if spec.TimeRange.From != "" {
+		db.Time = spec.TimeRange
+		if db.Time.To == "" {
+			db.Time.To = "now"
+		}
+	}
+	var datasource interface{}
+	if spec.Datasource != "" {
+		datasource = spec.Datasource
+	}
+	for _, v := range spec.Variables {
+		if v.Name == "" || v.Query == "" {
+			errs = append(errs, GrafanaError{0, fmt.Sprintf("variable %q needs a name and a query", v.Name)})
+			continue
+		}
+		tpl := GetDefaultTemplate(v.Name, "", spec.Datasource)
+		tpl.Query = v.Query
+		tpl.Multi = v.Multi
+		tpl.IncludeAll = v.IncludeAll
+		if v.Label != "" {
+			tpl.Label = v.Label
+		}
+		db.Templating.List = append(db.Templating.List, tpl)
+	}
+	for i, p := range spec.Panels {
+		if p.Query == "" {
+			errs = append(errs, GrafanaError{0, fmt.Sprintf("panel %q has an empty query", p.Title)})
+			continue
+		}
+		panel, err := buildPanel(p)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		panel.ID = i + 1
+		panel.Datasource = datasource
+		row := GetDefaultRow(p.Title, p.Query)
+		row.Panels[0] = panel
+		db.Rows = append(db.Rows, row)
+	}
+	if len(errs) > 0 {
+		return db, joinErrors(errs)
+	}
+	return db, nil
+}
+
+// LoadSpecYAML reads a dashboard spec written in YAML, e.g. a file kept in git, to pass to BuildFromSpec.
+// Unknown fields are reported, so a misspelled key isn't silently dropped.
+func LoadSpecYAML(r io.Reader) (spec DashboardSpec, err error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	if err = yaml.UnmarshalStrict(data, &spec); err != nil {
+		return spec, GrafanaError{0, fmt.Sprintf("invalid dashboard spec: %s", err)}
+	}
+	return
+}
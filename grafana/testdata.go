@@ -0,0 +1,30 @@
+package grafana
+
+// TestDataDataSource is the name of the TestData datasource, which generates data without any database
+const TestDataDataSource = "TestData DB"
+
+// testDataScenarios are the TestData scenarios useful for graphs
+var testDataScenarios = map[string]bool{
+	"random_walk":              true,
+	"random_walk_table":        true,
+	"csv_metric_values":        true,
+	"predictable_pulse":        true,
+	"predictable_csv_wave":     true,
+	"no_data_points":           true,
+	"datapoints_outside_range": true,
+}
+
+// AddTestDataPanel adds a row with a graph of the TestData scenario, e.g. "random_walk",
+// for demos and tests without a real datasource, laid out on the grid on grid dashboards.
+// The dashboard is returned unchanged if scenario is unknown.
+func AddTestDataPanel(db Dashboard, title, scenario string) Dashboard {
+	if !testDataScenarios[scenario] {
+		return db
+	}
+	row := GetDefaultRow(title, "")
+	panel := row.Panels[0]
+	panel.Datasource = TestDataDataSource
+	panel.Targets = []Target{{RefID: "A", ScenarioID: scenario}}
+	row.Panels[0] = panel
+	return appendRow(db, row)
+}
@@ -0,0 +1,39 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// UserPreferences contains the preferences of the session user, overriding the organization ones
+type UserPreferences struct {
+	HomeDashboardID  int    `json:"homeDashboardId"`
+	HomeDashboardUID string `json:"homeDashboardUID,omitempty"`
+	Theme            string `json:"theme"`
+	Timezone         string `json:"timezone"`
+	WeekStart        string `json:"weekStart,omitempty"`
+}
+
+// GetUserPreferences returns the preferences of the session user.
+func (s *Session) GetUserPreferences() (prefs UserPreferences, err error) {
+	reqURL := s.url + "/api/user/preferences"
+	body, err := s.httpRequest("GET", reqURL, nil)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(body)
+	err = dec.Decode(&prefs)
+	return
+}
+
+// SetUserPreferences replaces the preferences of the session user, e.g. to land on the overview of their team.
+// Empty theme, timezone and week start fall back to the organization preferences.
+func (s *Session) SetUserPreferences(prefs UserPreferences) (err error) {
+	if err = validatePreferences(prefs.Theme, prefs.Timezone, prefs.WeekStart); err != nil {
+		return
+	}
+	reqURL := s.url + "/api/user/preferences"
+	jsonStr, _ := json.Marshal(prefs)
+	_, err = s.httpRequest("PUT", reqURL, bytes.NewBuffer(jsonStr))
+	return
+}
@@ -0,0 +1,104 @@
+package grafana
+
+import (
+	"fmt"
+	"strings"
+)
+
+// builtinDataSources are datasource names Grafana resolves itself and which are never listed.
+var builtinDataSources = map[string]bool{
+	"":                true,
+	"default":         true,
+	"-- Grafana --":   true,
+	"-- Mixed --":     true,
+	"-- Dashboard --": true,
+}
+
+// ValidateDashboardDataSources checks that every datasource named by a template or a panel exists on the server.
+// Template variable references like $datasource can't be resolved and are skipped.
+func (s *Session) ValidateDashboardDataSources(db Dashboard) []error {
+	dataSources, err := s.ListDataSources()
+	if err != nil {
+		return []error{err}
+	}
+	known := make(map[string]bool, len(dataSources))
+	for _, ds := range dataSources {
+		known[ds.Name] = true
+	}
+	return checkDataSources(db, known)
+}
+
+func checkDataSources(db Dashboard, known map[string]bool) []error {
+	errs := make([]error, 0)
+	missing := func(name string) bool {
+		return !builtinDataSources[name] && !strings.HasPrefix(name, "$") && !known[name]
+	}
+	for _, tpl := range db.Templating.List {
+		if missing(tpl.Datasource) {
+			errs = append(errs, GrafanaError{0, fmt.Sprintf("template %q uses unknown datasource %q", tpl.Name, tpl.Datasource)})
+		}
+	}
+	for _, panel := range allPanels(db) {
+		if name, ok := panel.Datasource.(string); ok && panel.Type != "row" && missing(name) {
+			errs = append(errs, GrafanaError{0, fmt.Sprintf("panel %q uses unknown datasource %q", panel.Title, name)})
+		}
+	}
+	return errs
+}
+
+// ValidateDashboard returns every problem found in the dashboard before upload:
+// duplicate panel ids, duplicate template names, empty queries, invalid null point or x axis modes
+// and legacy rows whose spans don't sum to 12. Grid panels and panels nested in row panels are checked too.
+// Panels with id 0 have not been numbered yet and are ignored by the duplicate check.
+func ValidateDashboard(db Dashboard) []error {
+	errs := make([]error, 0)
+	tplNames := make(map[string]bool)
+	for _, tpl := range db.Templating.List {
+		if tplNames[tpl.Name] {
+			errs = append(errs, GrafanaError{0, fmt.Sprintf("duplicate template variable %q", tpl.Name)})
+		}
+		tplNames[tpl.Name] = true
+	}
+	panelIDs := make(map[int]bool)
+	for _, panel := range allPanels(db) {
+		if panel.ID != 0 {
+			if panelIDs[panel.ID] {
+				errs = append(errs, GrafanaError{0, fmt.Sprintf("duplicate panel id %d", panel.ID)})
+			}
+			panelIDs[panel.ID] = true
+		}
+		if panel.Type == "row" {
+			continue
+		}
+		if panel.NullPointMode != "" && !NullPointMode(panel.NullPointMode).Valid() {
+			errs = append(errs, GrafanaError{0, fmt.Sprintf("panel %q has invalid nullPointMode %q", panel.Title, panel.NullPointMode)})
+		}
+		if panel.Xaxis.Mode != "" && !XaxisMode(panel.Xaxis.Mode).Valid() {
+			errs = append(errs, GrafanaError{0, fmt.Sprintf("panel %q has invalid xaxis mode %q", panel.Title, panel.Xaxis.Mode)})
+		}
+		for _, target := range panel.Targets {
+			if target.Query == "" && target.Measurement == "" && target.ScenarioID == "" && target.PanelID == 0 {
+				errs = append(errs, GrafanaError{0, fmt.Sprintf("panel %q target %s has an empty query", panel.Title, target.RefID)})
+			}
+		}
+	}
+	for i, row := range db.Rows {
+		span := 0
+		for _, panel := range row.Panels {
+			span += panel.Span
+		}
+		if len(row.Panels) > 0 && span != rowSpan {
+			errs = append(errs, GrafanaError{0, fmt.Sprintf("row %d spans sum to %d instead of %d", i, span, rowSpan)})
+		}
+	}
+	return errs
+}
+
+// joinErrors merges several errors into a single one.
+func joinErrors(errs []error) error {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return GrafanaError{0, strings.Join(msgs, "; ")}
+}